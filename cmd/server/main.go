@@ -1,21 +1,114 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"webgl-app/internal/config"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/net/chat"
+	"webgl-app/internal/net/replayhandler"
 	"webgl-app/internal/net/wshandler"
+	"webgl-app/internal/replay"
+	"webgl-app/internal/storage"
 )
 
 func main() {
-	ws := wshandler.NewWebSocket()
+	cfg, err := config.LoadServerConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal(fmt.Errorf("invalid configuration: %w", err))
+	}
 
-	http.Handle("/", http.FileServer(http.Dir(filepath.Join("static"))))
-	http.HandleFunc("/ws", ws.WebSocketHandler)
+	level, _ := config.ParseLogLevel(cfg.LogLevel)
+	setupLogging(level)
+
+	characters, err := character.LoadCharacters(filepath.Join(cfg.HTTP.StaticRoot, "assets", "meta"))
+	if err != nil {
+		fatal(err)
+	}
+
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.Path)
+	if err != nil {
+		fatal(err)
+	}
+	go closeOnSignal(store)
 
-	log.Println("Server started at :8080")
-	err := http.ListenAndServe("0.0.0.0:8080", nil)
+	replays, err := replay.NewStore(cfg.Replays.Path, cfg.Replays.MaxCount, cfg.Replays.MaxAge)
 	if err != nil {
-		log.Fatal(err)
+		closeAndFatal(store, err)
+	}
+
+	ws := wshandler.NewWebSocket(characters, store, replays, serverSettings(cfg))
+
+	http.Handle("/", http.FileServer(http.Dir(cfg.HTTP.StaticRoot)))
+	http.HandleFunc("/ws", ws.WebSocketHandler)
+	http.Handle(replayhandler.PathPrefix, replayhandler.NewReplayHandler(replays))
+
+	if cfg.HTTP.TLSCert != "" {
+		slog.Info("Server started", "addr", cfg.HTTP.Addr, "tls", true)
+		err = http.ListenAndServeTLS(cfg.HTTP.Addr, cfg.HTTP.TLSCert, cfg.HTTP.TLSKey, nil)
+	} else {
+		slog.Info("Server started", "addr", cfg.HTTP.Addr, "tls", false)
+		err = http.ListenAndServe(cfg.HTTP.Addr, nil)
+	}
+	closeAndFatal(store, err)
+}
+
+func serverSettings(cfg config.ServerConfig) wshandler.Settings {
+	settings := wshandler.DefaultSettings()
+	settings.AllowedOrigins = cfg.HTTP.AllowedOrigins
+	settings.Player.WriteTimeout = cfg.Timeouts.Write
+	settings.PongTimeout = cfg.Timeouts.Pong
+	settings.IdleTimeout = cfg.Timeouts.Idle
+	settings.SessionGracePeriod = cfg.Timeouts.SessionGrace
+	settings.Limits.MaxMessageSize = cfg.Limits.MaxMessageSize
+	settings.Limits.MaxConnectionsPerIP = cfg.Limits.MaxConnectionsPerIP
+	settings.Rooms.MaxRooms = cfg.Limits.MaxRooms
+	settings.Rooms.MaxRoomsPerOwner = cfg.Limits.MaxRoomsPerOwner
+	if len(cfg.Chat.BannedWords) > 0 {
+		settings.Chat.Filters = append(settings.Chat.Filters, chat.NewWordFilter(cfg.Chat.BannedWords))
+	}
+
+	return settings
+}
+
+func closeOnSignal(store storage.Storage) {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	if err := store.Close(); err != nil {
+		fatal(err)
+	}
+	os.Exit(0)
+}
+
+func setupLogging(level slog.Level) {
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+
+	log.SetFlags(0)
+	log.SetOutput(slog.NewLogLogger(handler, slog.LevelInfo).Writer())
+}
+
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
+}
+
+func closeAndFatal(store storage.Storage, err error) {
+	if closeErr := store.Close(); closeErr != nil {
+		slog.Error(closeErr.Error())
 	}
+	fatal(err)
 }
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	EnvPrefix  = "WEBGL_APP_"
+	ConfigFlag = "config"
+)
+
+type fileValue struct {
+	line  int
+	key   string
+	value string
+}
+
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	*l = (*l)[:0]
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// LoadServerConfig resolves every server option from, in increasing order of
+// precedence: the defaults in ServerProgramConfig, a "key = value" config file
+// given by -config or WEBGL_APP_CONFIG, WEBGL_APP_* environment variables and
+// command-line flags. File keys match the flag names, environment variables
+// are the flag names upper-cased with dashes replaced by underscores.
+func LoadServerConfig(args []string) (ServerConfig, error) {
+	cfg := ServerProgramConfig
+	cfg.HTTP.AllowedOrigins = append([]string(nil), cfg.HTTP.AllowedOrigins...)
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String(ConfigFlag, "", "path to a key = value config file")
+	bindServerFlags(fs, &cfg)
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	path := *configPath
+	if path == "" {
+		path = os.Getenv(EnvName(ConfigFlag))
+	}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return cfg, err
+		}
+
+		for _, v := range values {
+			if v.key == ConfigFlag || fs.Lookup(v.key) == nil {
+				return cfg, fmt.Errorf("%s:%d: unknown option %q", path, v.line, v.key)
+			}
+			if explicit[v.key] {
+				continue
+			}
+			if err := fs.Set(v.key, v.value); err != nil {
+				return cfg, fmt.Errorf("%s:%d: invalid value for %s: %v", path, v.line, v.key, err)
+			}
+		}
+	}
+
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if envErr != nil || explicit[f.Name] || f.Name == ConfigFlag {
+			return
+		}
+
+		if value, exists := os.LookupEnv(EnvName(f.Name)); exists {
+			if err := fs.Set(f.Name, value); err != nil {
+				envErr = fmt.Errorf("invalid value for %s: %v", EnvName(f.Name), err)
+			}
+		}
+	})
+	if envErr != nil {
+		return cfg, envErr
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func bindServerFlags(fs *flag.FlagSet, cfg *ServerConfig) {
+	fs.StringVar(&cfg.HTTP.Addr, "addr", cfg.HTTP.Addr, "address the HTTP server listens on")
+	fs.StringVar(&cfg.HTTP.StaticRoot, "static-root", cfg.HTTP.StaticRoot, "directory with the client files")
+	fs.Var((*listValue)(&cfg.HTTP.AllowedOrigins), "allowed-origins", "comma-separated origins allowed to open a websocket, * allows any (default: same host only)")
+	fs.StringVar(&cfg.HTTP.TLSCert, "tls-cert", cfg.HTTP.TLSCert, "TLS certificate file, enables HTTPS together with -tls-key")
+	fs.StringVar(&cfg.HTTP.TLSKey, "tls-key", cfg.HTTP.TLSKey, "TLS private key file")
+
+	fs.IntVar(&cfg.Limits.MaxRooms, "max-rooms", cfg.Limits.MaxRooms, "maximum number of open rooms, 0 for no limit")
+	fs.IntVar(&cfg.Limits.MaxRoomsPerOwner, "max-rooms-per-owner", cfg.Limits.MaxRoomsPerOwner, "maximum number of open rooms created by one player, 0 for no limit")
+	fs.IntVar(&cfg.Limits.MaxConnectionsPerIP, "max-connections-per-ip", cfg.Limits.MaxConnectionsPerIP, "maximum number of websocket connections from one IP, 0 for no limit")
+	fs.Int64Var(&cfg.Limits.MaxMessageSize, "max-message-size", cfg.Limits.MaxMessageSize, "maximum size of an inbound websocket message in bytes, 0 for no limit")
+
+	fs.DurationVar(&cfg.Timeouts.Idle, "idle-timeout", cfg.Timeouts.Idle, "disconnect clients that send nothing for this long, 0 to disable")
+	fs.DurationVar(&cfg.Timeouts.Pong, "pong-timeout", cfg.Timeouts.Pong, "time to wait for a pong after a ping")
+	fs.DurationVar(&cfg.Timeouts.Write, "write-timeout", cfg.Timeouts.Write, "time limit for a single websocket write")
+	fs.DurationVar(&cfg.Timeouts.SessionGrace, "session-grace-period", cfg.Timeouts.SessionGrace, "how long a disconnected player keeps their seat")
+
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: debug, info, warn or error")
+
+	fs.Var((*listValue)(&cfg.Chat.BannedWords), "chat-banned-words", "comma-separated words masked in chat messages")
+
+	fs.StringVar(&cfg.Storage.Driver, "storage-driver", cfg.Storage.Driver, "storage driver: file or memory")
+	fs.StringVar(&cfg.Storage.Path, "storage-path", cfg.Storage.Path, "directory of the file storage")
+	fs.StringVar(&cfg.Replays.Path, "replays-path", cfg.Replays.Path, "directory where replays are saved")
+	fs.IntVar(&cfg.Replays.MaxCount, "replays-max-count", cfg.Replays.MaxCount, "maximum number of kept replays, 0 for no limit")
+	fs.DurationVar(&cfg.Replays.MaxAge, "replays-max-age", cfg.Replays.MaxAge, "delete replays older than this, 0 to keep them")
+}
+
+func readConfigFile(path string) ([]fileValue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make([]fileValue, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+
+		values = append(values, fileValue{
+			line:  line,
+			key:   strings.TrimSpace(key),
+			value: value,
+		})
+	}
+
+	return values, scanner.Err()
+}
+
+func (c ServerConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.HTTP.Addr); err != nil {
+		return fmt.Errorf("invalid listen address %q: %v", c.HTTP.Addr, err)
+	}
+
+	if info, err := os.Stat(c.HTTP.StaticRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("static root %q is not a directory", c.HTTP.StaticRoot)
+	}
+
+	for _, origin := range c.HTTP.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid allowed origin %q, expected scheme://host[:port]", origin)
+		}
+	}
+
+	if (c.HTTP.TLSCert == "") != (c.HTTP.TLSKey == "") {
+		return fmt.Errorf("tls-cert and tls-key must be set together")
+	}
+	for _, path := range []string{c.HTTP.TLSCert, c.HTTP.TLSKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("tls file %q: %v", path, err)
+		}
+	}
+
+	if c.Limits.MaxRooms < 0 || c.Limits.MaxRoomsPerOwner < 0 || c.Limits.MaxConnectionsPerIP < 0 || c.Limits.MaxMessageSize < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+
+	if c.Timeouts.Pong <= 0 || c.Timeouts.Write <= 0 {
+		return fmt.Errorf("pong and write timeouts must be positive")
+	}
+	if c.Timeouts.Idle < 0 || c.Timeouts.SessionGrace < 0 {
+		return fmt.Errorf("idle timeout and session grace period cannot be negative")
+	}
+
+	if _, err := ParseLogLevel(c.LogLevel); err != nil {
+		return err
+	}
+
+	if c.Replays.MaxCount < 0 || c.Replays.MaxAge < 0 {
+		return fmt.Errorf("replay limits cannot be negative")
+	}
+
+	return nil
+}
+
+func ParseLogLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", level)
+	}
+	return l, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import "time"
+
+type HTTP struct {
+	Addr           string
+	StaticRoot     string
+	AllowedOrigins []string
+	TLSCert        string
+	TLSKey         string
+}
+
+type Limits struct {
+	MaxRooms            int
+	MaxRoomsPerOwner    int
+	MaxConnectionsPerIP int
+	MaxMessageSize      int64
+}
+
+type Timeouts struct {
+	Idle         time.Duration
+	Pong         time.Duration
+	Write        time.Duration
+	SessionGrace time.Duration
+}
+
+type Chat struct {
+	BannedWords []string
+}
+
+type Storage struct {
+	Driver string
+	Path   string
+}
+
+type Replays struct {
+	Path     string
+	MaxCount int
+	MaxAge   time.Duration
+}
+
+type ServerConfig struct {
+	HTTP     HTTP
+	Limits   Limits
+	Timeouts Timeouts
+	LogLevel string
+	Chat     Chat
+	Storage  Storage
+	Replays  Replays
+}
+
+var ServerProgramConfig = ServerConfig{
+	HTTP: HTTP{
+		Addr:       "0.0.0.0:8080",
+		StaticRoot: "static",
+	},
+	Limits: Limits{
+		MaxRooms:            1000,
+		MaxRoomsPerOwner:    3,
+		MaxConnectionsPerIP: 8,
+		MaxMessageSize:      16 * 1024,
+	},
+	Timeouts: Timeouts{
+		Idle:         10 * time.Minute,
+		Pong:         10 * time.Second,
+		Write:        10 * time.Second,
+		SessionGrace: 30 * time.Second,
+	},
+	LogLevel: "info",
+	Storage: Storage{
+		Driver: "file",
+		Path:   "data",
+	},
+	Replays: Replays{
+		Path:     "data/replays",
+		MaxCount: 500,
+		MaxAge:   7 * 24 * time.Hour,
+	},
+}
@@ -0,0 +1,35 @@
+//go:build js
+
+package config
+
+import (
+	"syscall/js"
+	"webgl-app/internal/resourceloader"
+	"webgl-app/internal/utils"
+)
+
+func LoadSources(path string, v any) error {
+	var (
+		errLoad error
+	)
+
+	done := make(chan struct{}, 0)
+
+	resourceloader.LoadFile(path,
+		func(src js.Value) {
+			errLoad = utils.ParseStringToJSON(src.String(), &v)
+			close(done)
+		},
+		func(err error) {
+			errLoad = err
+			close(done)
+		})
+
+	<-done
+
+	if errLoad != nil {
+		return errLoad
+	}
+
+	return nil
+}
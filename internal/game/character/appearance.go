@@ -0,0 +1,29 @@
+//go:build js
+
+package character
+
+import (
+	"webgl-app/internal/graphics/animation"
+	"webgl-app/internal/graphics/webgl"
+)
+
+type Appearance struct {
+	Sprite     *webgl.Sprite
+	Animations map[string]*animation.Animation
+}
+
+func (c *Character) AddAnimation(aType string, anim *animation.Animation) {
+	if c.Animations == nil {
+		c.Animations = make(map[string]*animation.Animation)
+	}
+	c.Animations[aType] = anim
+	c.Clips[aType] = anim.Clip
+}
+
+func (c *Character) SetAnimations(anims map[string]*animation.Animation) {
+	c.Animations = anims
+	c.Clips = make(map[string]animation.Clip, len(anims))
+	for aType, anim := range anims {
+		c.Clips[aType] = anim.Clip
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !js
+
+package character
+
+type Appearance struct{}
@@ -1,10 +1,7 @@
-//go:build js
-
 package character
 
 import (
 	"webgl-app/internal/graphics/animation"
-	"webgl-app/internal/graphics/webgl"
 )
 
 type AttackProperties struct {
@@ -22,25 +19,20 @@ type CharacterProperties struct {
 }
 
 type Character struct {
-	Name       string
-	Sprite     *webgl.Sprite
-	Animations map[string]*animation.Animation
-	Properies  CharacterProperties
+	Name      string
+	Clips     map[string]animation.Clip
+	Properies CharacterProperties
+	Appearance
 }
 
-func NewCharacter(name string, sprite *webgl.Sprite, properies CharacterProperties) *Character {
+func NewCharacter(name string, properies CharacterProperties) *Character {
 	return &Character{
-		Name:       name,
-		Sprite:     sprite,
-		Animations: make(map[string]*animation.Animation),
-		Properies:  properies,
+		Name:      name,
+		Clips:     make(map[string]animation.Clip),
+		Properies: properies,
 	}
 }
 
-func (c *Character) AddAnimation(aType string, anim *animation.Animation) {
-	c.Animations[aType] = anim
-}
-
-func (c *Character) SetAnimations(anims map[string]*animation.Animation) {
-	c.Animations = anims
+func (c *Character) SetClips(clips map[string]animation.Clip) {
+	c.Clips = clips
 }
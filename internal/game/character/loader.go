@@ -0,0 +1,40 @@
+package character
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"webgl-app/internal/graphics/animation"
+	"webgl-app/internal/utils"
+)
+
+func LoadCharacters(metaDir string) (map[string]*Character, error) {
+	warriorClips, err := loadClips(filepath.Join(metaDir, "warrior_meta.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	warriorChar := NewCharacter(WarriorName, WarriorProperties)
+	warriorChar.SetClips(warriorClips)
+
+	return map[string]*Character{
+		WarriorName: warriorChar,
+	}, nil
+}
+
+func loadClips(path string) (map[string]animation.Clip, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta animation.AnimationsMeta
+	if err := utils.ParseStringToJSON(string(src), &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if meta.Parameters.Type != animation.TypeAnimationsSpritesheet {
+		return nil, fmt.Errorf("Incorrect type")
+	}
+
+	return animation.NewClipsSet(meta), nil
+}
@@ -0,0 +1,21 @@
+package character
+
+const WarriorName = "warrior"
+
+var WarriorProperties = CharacterProperties{
+	HealthPoints: 100,
+	Attack1: AttackProperties{
+		FrameIndex: 5,
+		Damage:     9,
+		Range:      110,
+		Height:     160,
+		Up:         40,
+	},
+	Attack2: AttackProperties{
+		FrameIndex: 1,
+		Damage:     6,
+		Range:      80,
+		Height:     120,
+		Up:         30,
+	},
+}
@@ -0,0 +1,27 @@
+//go:build js
+
+package fighter
+
+import (
+	"webgl-app/internal/config"
+	"webgl-app/internal/graphics/primitives"
+	"webgl-app/internal/graphics/webgl"
+)
+
+func (f *Fighter) Draw(glCtx *webgl.GLContext) {
+	f.DrawAt(glCtx, f.Colliders.HitBox)
+}
+
+func (f *Fighter) DrawAt(glCtx *webgl.GLContext, hitBox primitives.Rect) {
+	anim := f.Character.Animations[string(f.AnimationState)]
+	glCtx.RenderSprite(anim.GetFrame(f.Animation.CurrentFrameIndex), hitBox, f.Properties.Specular)
+
+	if config.ProgramConfig.Debug {
+		f.DrawColliders(glCtx, hitBox)
+	}
+}
+
+func (f *Fighter) DrawColliders(glCtx *webgl.GLContext, hitBox primitives.Rect) {
+	glCtx.RenderRect(hitBox, webgl.ColorBlue(1.0))
+	glCtx.RenderRect(f.Colliders.Attack, webgl.ColorRed(1.0))
+}
@@ -1,5 +1,3 @@
-//go:build js
-
 package fighter
 
 import (
@@ -8,7 +6,6 @@ import (
 	"webgl-app/internal/game/character"
 	"webgl-app/internal/graphics/animation"
 	"webgl-app/internal/graphics/primitives"
-	"webgl-app/internal/graphics/webgl"
 	"webgl-app/internal/net/message"
 )
 
@@ -60,7 +57,7 @@ type Fighter struct {
 	AnimationState FighterState
 	Colliders      Colliders
 	Character      *character.Character
-	Animation      animation.Animation
+	Animation      animation.Clip
 	Properties     FighterProperties
 	Control        message.FighterControl
 	HoldingKeys    HoldingKeys
@@ -86,7 +83,7 @@ func NewFighter(char *character.Character, posX float64) *Fighter {
 		State:      Idle,
 		Colliders:  colliders,
 		Character:  char,
-		Animation:  *char.Animations[string(Idle)],
+		Animation:  char.Clips[string(Idle)],
 		Properties: Properties,
 	}
 
@@ -96,16 +93,7 @@ func NewFighter(char *character.Character, posX float64) *Fighter {
 func (f *Fighter) updateAnimationState() {
 	if f.AnimationState != f.State {
 		f.AnimationState = f.State
-		f.Animation = *f.Character.Animations[string(f.AnimationState)]
-	}
-}
-
-func (f *Fighter) Draw(glCtx *webgl.GLContext) {
-	glCtx.RenderSprite(f.Animation.GetCurrentFrame(), f.Colliders.HitBox, f.Properties.Specular)
-
-	if config.ProgramConfig.Debug {
-		glCtx.RenderRect(f.Colliders.HitBox, webgl.ColorBlue(1.0))
-		glCtx.RenderRect(f.Colliders.Attack, webgl.ColorRed(1.0))
+		f.Animation = f.Character.Clips[string(f.AnimationState)]
 	}
 }
 
@@ -164,7 +152,7 @@ func (f *Fighter) Update(deltaTime time.Duration, enemyFighter *Fighter) {
 	f.handleState()
 	f.updateAnimationState()
 
-	f.Animation.Update(float64(deltaTime.Milliseconds()))
+	f.Animation.Update(float64(deltaTime) / float64(time.Millisecond))
 }
 
 func (f *Fighter) move(dx *float64, efc primitives.Vec2) {
@@ -0,0 +1,51 @@
+package fighter
+
+import (
+	"math"
+	"webgl-app/internal/graphics/primitives"
+	"webgl-app/internal/net/message"
+)
+
+const snapshotTolerance = 0.01
+
+func (f *Fighter) Snapshot(id string) message.FighterSnapshot {
+	return message.FighterSnapshot{
+		ID:             id,
+		CharacterName:  f.Character.Name,
+		State:          string(f.State),
+		AnimationFrame: f.Animation.CurrentFrameIndex,
+		HealthPoints:   f.Properties.HealthPoints,
+		Specular:       f.Properties.Specular,
+		HitBox:         f.Colliders.HitBox,
+		AttackBox:      f.Colliders.Attack,
+	}
+}
+
+func (f *Fighter) ApplySnapshot(snapshot message.FighterSnapshot) {
+	f.State = FighterState(snapshot.State)
+	f.updateAnimationState()
+	f.Animation.CurrentFrameIndex = snapshot.AnimationFrame
+	f.Properties.HealthPoints = snapshot.HealthPoints
+	f.Properties.Specular = snapshot.Specular
+	f.Colliders.HitBox = snapshot.HitBox
+	f.Colliders.Attack = snapshot.AttackBox
+}
+
+func SameSnapshot(a, b message.FighterSnapshot) bool {
+	return a.ID == b.ID &&
+		a.State == b.State &&
+		a.AnimationFrame == b.AnimationFrame &&
+		a.Specular == b.Specular &&
+		closeTo(a.HealthPoints, b.HealthPoints) &&
+		sameRect(a.HitBox, b.HitBox) &&
+		sameRect(a.AttackBox, b.AttackBox)
+}
+
+func sameRect(a, b primitives.Rect) bool {
+	return closeTo(a.Pos.X, b.Pos.X) && closeTo(a.Pos.Y, b.Pos.Y) &&
+		closeTo(a.Size.X, b.Size.X) && closeTo(a.Size.Y, b.Size.Y)
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= snapshotTolerance
+}
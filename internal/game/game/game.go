@@ -3,16 +3,16 @@
 package game
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
 	"syscall/js"
 	"time"
 	"webgl-app/internal/assetsmanager"
-	"webgl-app/internal/config"
 	"webgl-app/internal/game/character"
 	"webgl-app/internal/game/fighter"
 	"webgl-app/internal/game/level"
+	"webgl-app/internal/game/match"
+	"webgl-app/internal/game/rollback"
 	"webgl-app/internal/graphics/animation"
 	"webgl-app/internal/graphics/primitives"
 	"webgl-app/internal/graphics/webgl"
@@ -22,19 +22,32 @@ import (
 )
 
 type GameState struct {
-	isStart       bool
-	isEnd         bool
-	startCooldown float64
-	endCooldown   float64
+	isStart bool
+	isEnd   bool
 }
 
 type Game struct {
 	gameState    GameState
 	fighters     []*fighter.Fighter
+	prevHitBoxes []primitives.Rect
+	nameTags     []*webgl.Sprite
+	rounds       rounds
+	playerID     string
+	spectator    bool
+	swapped      bool
+	match        *match.Match
+	session      *rollback.Session
+	pending      *message.GameSnapshot
+	playback     *playback
+	startAt      time.Time
+	countdownEnd time.Time
+	countdown    int
+	loopID       int
 	running      bool
-	socket       *js.Value
+	send         func(message.Message)
 	glCtx        *webgl.GLContext
 	keys         map[string]bool
+	escapeHeld   bool
 	assets       *assetsmanager.AssetsManager
 	levels       map[string]*level.Level
 	titles       map[string]*webgl.Sprite
@@ -43,12 +56,18 @@ type Game struct {
 	currentLevel *level.Level
 }
 
+const (
+	maxCatchUpFrames = 5
+	maxFrameTime     = maxCatchUpFrames * match.TickDuration
+	nameTagOffset    = 80
+)
+
 var (
 	Direction primitives.Vec2
 	Speed     float64
 )
 
-func NewGame(socket *js.Value, glCtx *webgl.GLContext) (*Game, error) {
+func NewGame(send func(message.Message), glCtx *webgl.GLContext) (*Game, error) {
 	jsfunc.LogInfo(" ----- Loading assets ----- ")
 	assets := assetsmanager.NewAssetsManager()
 	err := assets.Load(glCtx, "assets_config.json")
@@ -57,7 +76,7 @@ func NewGame(socket *js.Value, glCtx *webgl.GLContext) (*Game, error) {
 	}
 
 	game := Game{
-		socket: socket,
+		send:   send,
 		glCtx:  glCtx,
 		keys:   make(map[string]bool),
 		assets: assets,
@@ -83,9 +102,43 @@ func NewGame(socket *js.Value, glCtx *webgl.GLContext) (*Game, error) {
 		return nil, err
 	}
 
+	js.Global().Call("addEventListener", "keydown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if isTextInput(args[0].Get("target")) {
+			return nil
+		}
+
+		code := args[0].Get("code").String()
+		game.keys[code] = true
+
+		return nil
+	}))
+	js.Global().Call("addEventListener", "keyup", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		code := args[0].Get("code").String()
+		game.keys[code] = false
+
+		return nil
+	}))
+
 	return &game, nil
 }
 
+func isTextInput(target js.Value) bool {
+	if target.IsUndefined() || target.IsNull() || target.Get("tagName").IsUndefined() {
+		return false
+	}
+
+	tagName := target.Get("tagName").String()
+	return tagName == "INPUT" || tagName == "TEXTAREA" || tagName == "SELECT"
+}
+
+func (g *Game) escapePressed() bool {
+	held := g.keys["Escape"]
+	pressed := held && !g.escapeHeld
+	g.escapeHeld = held
+
+	return pressed
+}
+
 func (g *Game) createLevels(assets *assetsmanager.AssetsManager) error {
 	g.levels = make(map[string]*level.Level)
 
@@ -111,6 +164,8 @@ func (g *Game) createTitles(assets *assetsmanager.AssetsManager) error {
 
 	texStart := assets.GetTexture("start")
 	g.titles["start"] = webgl.NewSprite(texStart, &primitives.Rect{Pos: primitives.NewVec2(0, 0), Size: primitives.NewVec2(texStart.Width, texStart.Height)}, 1.5, nil, nil)
+
+	g.createRoundPips()
 	return nil
 }
 
@@ -121,27 +176,11 @@ func (g *Game) createCharacters(assets *assetsmanager.AssetsManager) error {
 	if err != nil {
 		return err
 	}
-	warriorCharProperties := character.CharacterProperties{
-		HealthPoints: 100,
-		Attack1: character.AttackProperties{
-			FrameIndex: 5,
-			Damage:     9,
-			Range:      110,
-			Height:     160,
-			Up:         40,
-		},
-		Attack2: character.AttackProperties{
-			FrameIndex: 1,
-			Damage:     6,
-			Range:      80,
-			Height:     120,
-			Up:         30,
-		},
-	}
-	warriorChar := character.NewCharacter("warrior", warriorAnim["idle"].GetCurrentFrame(), warriorCharProperties)
+	warriorChar := character.NewCharacter(character.WarriorName, character.WarriorProperties)
 	warriorChar.SetAnimations(warriorAnim)
+	warriorChar.Sprite = warriorAnim["idle"].GetCurrentFrame()
 
-	g.characters["warrior"] = warriorChar
+	g.characters[character.WarriorName] = warriorChar
 
 	return nil
 }
@@ -169,88 +208,176 @@ func (g *Game) createHealthBar(assets *assetsmanager.AssetsManager) error {
 	return nil
 }
 
-func (g *Game) Start(playerId string, fightersPositions map[string]int) {
+func (g *Game) Start(playerId string, gameData message.StartGameData, startAt time.Time) {
+	slot, err := g.setupMatch(playerId, gameData, character.WarriorName)
+	if err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	g.startAt = startAt
+	g.countdownEnd = startAt.Add(time.Duration(gameData.Countdown) * time.Millisecond)
+
+	g.session = rollback.NewSession(g.match, len(g.fighters), slot, gameData.InputDelay, gameData.RollbackWindow)
+	g.savePrevHitBoxes()
+
+	g.renderLoop()
+}
+
+func (g *Game) setupMatch(playerId string, gameData message.StartGameData, characterName string) (int, error) {
 	g.gameState = GameState{
-		isStart:       false,
-		isEnd:         false,
-		startCooldown: 2,
-		endCooldown:   3,
+		isStart: false,
+		isEnd:   false,
 	}
 
 	g.fighters = make([]*fighter.Fighter, 2)
+	g.prevHitBoxes = make([]primitives.Rect, 2)
+	g.playerID = playerId
 
 	g.currentLevel = g.levels["level_1"]
+	if lvl, exists := g.levels[gameData.Level]; exists {
+		g.currentLevel = lvl
+	}
 
-	js.Global().Call("addEventListener", "keydown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		code := args[0].Get("code").String()
-		g.keys[code] = true
+	char, exists := g.characters[characterName]
+	if !exists {
+		return -1, fmt.Errorf("unknown character %s", characterName)
+	}
 
-		return nil
-	}))
-	js.Global().Call("addEventListener", "keyup", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		code := args[0].Get("code").String()
-		g.keys[code] = false
+	var err error
+	g.match, err = match.NewMatch(char, gameData.FightersPositions)
+	if err != nil {
+		return -1, err
+	}
+	if gameData.Countdown > 0 {
+		g.match.SetStartCooldown(time.Duration(gameData.Countdown) * time.Millisecond)
+	}
+	if gameData.BestOf > 0 {
+		g.match.SetBestOf(gameData.BestOf)
+	}
 
-		return nil
-	}))
+	slot, ok := g.match.Slot(playerId)
+	if !ok {
+		slot = -1
+	}
+	g.spectator = slot < 0
+	g.swapped = slot == 1
+	g.resetRounds(gameData.BestOf)
 
-	positions := []float64{
-		config.ProgramConfig.Window.Width / 4,
-		config.ProgramConfig.Window.Width - config.ProgramConfig.Window.Width/4,
+	fighters := g.match.Fighters()
+	if slot == 1 {
+		g.fighters[0], g.fighters[1] = fighters[1], fighters[0]
+	} else {
+		g.fighters[0], g.fighters[1] = fighters[0], fighters[1]
 	}
 
-	for id, fighterPos := range fightersPositions {
-		if playerId == id {
-			g.fighters[0] = fighter.NewFighter(g.characters["warrior"], positions[fighterPos])
-		} else {
-			g.fighters[1] = fighter.NewFighter(g.characters["warrior"], positions[fighterPos])
+	g.createNameTags(g.match.Players(), gameData.PlayerNames, slot)
+
+	return slot, nil
+}
+
+func (g *Game) createNameTags(ids []string, names map[string]string, slot int) {
+	g.deleteNameTags()
+
+	g.nameTags = make([]*webgl.Sprite, len(ids))
+	for i, id := range ids {
+		name, exists := names[id]
+		if !exists || name == "" {
+			continue
 		}
-	}
 
-	if config.ProgramConfig.Debug {
-		if g.fighters[1] == nil {
-			g.fighters[1] = fighter.NewFighter(g.characters["warrior"], positions[1])
+		tex := webgl.NewTexture(g.glCtx, jsfunc.CreateTextImage(name))
+		if tex == nil {
+			continue
 		}
+		g.nameTags[i] = webgl.NewSprite(tex, &primitives.Rect{Pos: primitives.NewVec2(0, 0), Size: primitives.NewVec2(tex.Width, tex.Height)}, 1, nil, nil)
 	}
 
-	g.renderLoop()
+	if slot == 1 {
+		g.nameTags[0], g.nameTags[1] = g.nameTags[1], g.nameTags[0]
+	}
+}
+
+func (g *Game) deleteNameTags() {
+	for _, tag := range g.nameTags {
+		if tag != nil {
+			tag.Texture.Delete(g.glCtx)
+		}
+	}
+	g.nameTags = nil
 }
 
 func (g *Game) Stop() {
 	g.running = false
 	g.keys = make(map[string]bool)
+	g.escapeHeld = true
+	g.match = nil
+	g.session = nil
+	g.pending = nil
+	g.startAt = time.Time{}
+	g.countdownEnd = time.Time{}
+	g.deleteNameTags()
+	g.deleteRoundCaption()
+	g.updateCountdown(0)
+
+	if g.playback != nil {
+		g.playback = nil
+		jsfunc.ShowReplayControls(false)
+	}
 }
 
 func (g *Game) renderLoop() {
 	var (
 		renderFrame   js.Func
-		frameTime     = time.Second / time.Duration(config.ProgramConfig.Window.FrameRate)
-		lastFrameTime time.Time
-		elapsedTime   time.Duration
+		lastTimestamp float64
+		accumulator   time.Duration
+		started       bool
 	)
 
-	lastFrameTime = time.Now()
-
 	g.running = true
+	g.loopID++
+	loopID := g.loopID
+
 	renderFrame = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		if !g.running {
+		if !g.running || loopID != g.loopID {
+			renderFrame.Release()
 			return nil
 		}
 
-		currentTime := time.Now()
-		deltaTime := currentTime.Sub(lastFrameTime)
-		lastFrameTime = currentTime
+		speed := g.timeScale()
 
-		g.update(deltaTime)
-		g.draw()
+		timestamp := args[0].Float()
+		if lastTimestamp == 0 {
+			lastTimestamp = timestamp
+		}
+		accumulator += time.Duration((timestamp - lastTimestamp) * float64(time.Millisecond) * speed)
+		lastTimestamp = timestamp
+
+		now := time.Now()
+		if now.Before(g.startAt) {
+			accumulator = 0
+		} else if !started {
+			started = true
+			if !g.startAt.IsZero() {
+				accumulator = now.Sub(g.startAt)
+			}
+		}
 
-		g.sendPlayerState()
+		if maxTime := time.Duration(float64(maxFrameTime) * math.Max(speed, 1)); accumulator > maxTime {
+			accumulator = maxTime
+		}
+
+		if g.playback == nil {
+			g.updateCountdown(int(math.Ceil(g.countdownEnd.Sub(now).Seconds())))
+		}
 
-		elapsedTime = time.Now().Sub(currentTime)
-		if elapsedTime < frameTime {
-			time.Sleep(frameTime - elapsedTime)
+		for accumulator >= match.TickDuration {
+			g.update()
+			accumulator -= match.TickDuration
 		}
 
+		g.draw(float64(accumulator) / float64(match.TickDuration))
+
 		js.Global().Call("requestAnimationFrame", renderFrame)
 		return nil
 	})
@@ -258,46 +385,70 @@ func (g *Game) renderLoop() {
 	js.Global().Call("requestAnimationFrame", renderFrame)
 }
 
-func (g *Game) update(deltaTime time.Duration) {
+func (g *Game) update() {
 	if !g.running || g.fighters[0] == nil || g.fighters[1] == nil {
 		return
 	}
 
-	if !g.gameState.isStart {
-		g.gameState.startCooldown -= deltaTime.Seconds()
-		if g.gameState.startCooldown <= 0 {
-			g.gameState.isStart = true
-		}
+	if g.playback != nil {
+		g.updatePlayback()
+		return
 	}
-	if g.gameState.isEnd {
-		g.gameState.endCooldown -= deltaTime.Seconds()
-		if g.gameState.endCooldown <= 0 {
-			g.sendEndGameMsg()
+
+	if g.escapePressed() {
+		if g.spectator {
+			g.sendLeaveRoomMsg()
+			g.Stop()
+			return
 		}
+		g.sendEndGameMsg()
 	}
 
-	if g.keys["Escape"] {
-		g.sendEndGameMsg()
+	if g.session == nil {
+		return
 	}
 
-	if g.gameState.isStart {
-		g.fighters[0].Control = message.FighterControl{
-			MoveLeft:  g.keys["KeyA"],
-			MoveRight: g.keys["KeyD"],
-			Jump:      g.keys["Space"],
-			Attack:    g.keys["KeyJ"],
+	g.savePrevHitBoxes()
+
+	for i := 0; i < maxCatchUpFrames; i++ {
+		if g.session.CanAdvance() && !g.spectator {
+			g.sendPlayerInput(g.session.AddLocalInput(g.localControl()))
+		}
+		if !g.session.Advance() || g.session.Frame() >= g.session.ConfirmedFrame() {
+			break
 		}
 	}
+	if g.pending != nil && g.pending.Tick <= g.session.Frame() {
+		g.reconcile(*g.pending)
+		g.pending = nil
+	}
+
+	g.gameState.isStart = g.match.IsStart()
+	g.gameState.isEnd = g.match.IsEnd()
+	g.syncRounds(g.match.Round(), g.match.Wins())
+}
+
+func (g *Game) updateCountdown(seconds int) {
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds == g.countdown {
+		return
+	}
 
-	g.fighters[0].Update(deltaTime, g.fighters[1])
-	g.fighters[1].Update(deltaTime, g.fighters[0])
+	g.countdown = seconds
+	jsfunc.UpdateCountdown(seconds)
+}
 
-	if g.fighters[0].State == fighter.Death || g.fighters[1].State == fighter.Death {
-		g.gameState.isEnd = true
+func (g *Game) savePrevHitBoxes() {
+	for i, f := range g.fighters {
+		if f != nil {
+			g.prevHitBoxes[i] = f.Colliders.HitBox
+		}
 	}
 }
 
-func (g *Game) draw() {
+func (g *Game) draw(alpha float64) {
 	if !g.running {
 		return
 	}
@@ -305,28 +456,66 @@ func (g *Game) draw() {
 	g.currentLevel.Draw(g.glCtx)
 
 	g.healthBarsDraw()
+	g.roundPipsDraw()
 
 	if g.fighters[1] != nil {
-		g.fighters[1].Draw(g.glCtx)
+		g.fighters[1].DrawAt(g.glCtx, g.interpolatedHitBox(1, alpha))
+	}
+	g.fighters[0].DrawAt(g.glCtx, g.interpolatedHitBox(0, alpha))
+
+	g.nameTagsDraw(alpha)
+
+	if g.playback != nil && g.playback.showColliders {
+		for i, f := range g.fighters {
+			f.DrawColliders(g.glCtx, g.interpolatedHitBox(i, alpha))
+		}
 	}
-	g.fighters[0].Draw(g.glCtx)
 
 	g.titleDraw()
 
 	g.glCtx.DrawQueue()
 }
 
+func (g *Game) interpolatedHitBox(index int, alpha float64) primitives.Rect {
+	hitBox := g.fighters[index].Colliders.HitBox
+	hitBox.Pos = g.prevHitBoxes[index].Pos.Lerp(hitBox.Pos, alpha)
+
+	return hitBox
+}
+
+func (g *Game) nameTagsDraw(alpha float64) {
+	for i, tag := range g.nameTags {
+		if tag == nil || g.fighters[i] == nil {
+			continue
+		}
+
+		hitBox := g.interpolatedHitBox(i, alpha)
+		pos := primitives.NewVec2(hitBox.Center().X-tag.Rect.Width()/2, hitBox.Top()-tag.Rect.Height()-nameTagOffset)
+		g.glCtx.RenderSprite(tag, primitives.Rect{Pos: pos}, false)
+	}
+}
+
 func (g *Game) titleDraw() {
 	if !g.gameState.isStart {
 		g.glCtx.RenderSprite(g.titles["start"], primitives.NewRect(530, -50, 0, 0), false)
 	}
-	if g.gameState.isEnd {
-		if g.fighters[0].State == fighter.Death {
+	if g.gameState.isEnd && !g.spectator {
+		if g.localDefeated() {
 			g.glCtx.RenderSprite(g.titles["defeat"], primitives.NewRect(440, 75, 0, 0), false)
 		} else {
 			g.glCtx.RenderSprite(g.titles["victory"], primitives.NewRect(440, 0, 0, 0), false)
 		}
 	}
+	if g.gameState.isEnd {
+		g.roundCaptionDraw()
+	}
+}
+
+func (g *Game) localDefeated() bool {
+	if g.matchDecided() {
+		return g.rounds.wins[0] < g.rounds.wins[1]
+	}
+	return g.fighters[0].State == fighter.Death
 }
 
 func (g *Game) healthBarsDraw() {
@@ -353,34 +542,100 @@ func (g *Game) healthBarsDraw() {
 	}
 }
 
-func (g *Game) sendPlayerState() {
-	msg := message.Message{
-		Type: message.GameStateMsg,
-		Data: message.FighterInfo{
-			CharacterName: g.fighters[0].Character.Name,
-			HealthPoints:  g.fighters[0].Properties.HealthPoints,
-			HitBox:        g.fighters[0].Colliders.HitBox,
-			Control: message.FighterControl{
-				MoveLeft:  g.keys["KeyA"],
-				MoveRight: g.keys["KeyD"],
-				Jump:      g.keys["Space"],
-				Attack:    g.keys["KeyJ"],
-			},
+func (g *Game) localControl() message.FighterControl {
+	return message.FighterControl{
+		MoveLeft:  g.keys["KeyA"],
+		MoveRight: g.keys["KeyD"],
+		Jump:      g.keys["Space"],
+		Attack:    g.keys["KeyJ"],
+	}
+}
+
+func (g *Game) sendPlayerInput(frame uint64) {
+	g.sendMessage(message.Message{
+		Type: message.PlayerInputMsg,
+		Data: message.FrameInput{
+			Frame:   frame,
+			Control: g.localControl(),
 		},
+	})
+}
+
+func (g *Game) ConfirmInputs(inputs message.ConfirmedInputs) {
+	if !g.running || g.session == nil {
+		return
 	}
 
-	g.sendMessage(msg)
+	g.session.Confirm(inputs.Frame, inputs.Controls)
 }
 
-func (g *Game) UpdatePlayersData(fighterInfo message.FighterInfo) {
-	if g.fighters[1].Character.Name != fighterInfo.CharacterName {
-		g.fighters[1].Character = g.characters[fighterInfo.CharacterName]
+func (g *Game) Resume(inputs []message.ConfirmedInputs) {
+	if !g.running || g.session == nil {
+		return
+	}
+
+	for _, frameInputs := range inputs {
+		g.session.Confirm(frameInputs.Frame, frameInputs.Controls)
 	}
-	g.fighters[1].Properties.HealthPoints = fighterInfo.HealthPoints
-	g.fighters[1].Colliders.HitBox = fighterInfo.HitBox
-	if g.gameState.isStart {
-		g.fighters[1].Control = fighterInfo.Control
+	for g.session.Frame() < g.session.ConfirmedFrame() {
+		if !g.session.Advance() {
+			break
+		}
 	}
+
+	g.savePrevHitBoxes()
+	g.gameState.isStart = g.match.IsStart()
+	g.gameState.isEnd = g.match.IsEnd()
+	g.syncRounds(g.match.Round(), g.match.Wins())
+}
+
+func (g *Game) ApplySnapshot(snapshot message.GameSnapshot) {
+	if !g.running || g.fighters[0] == nil || g.fighters[1] == nil {
+		return
+	}
+
+	if g.session != nil {
+		if snapshot.Tick > g.session.Frame() {
+			g.pending = &snapshot
+			return
+		}
+		g.pending = nil
+		g.reconcile(snapshot)
+		return
+	}
+
+	g.gameState.isStart = snapshot.IsStart
+	g.gameState.isEnd = snapshot.IsEnd
+	g.syncRounds(snapshot.Round, snapshot.Wins)
+
+	for _, fighterSnapshot := range snapshot.Fighters {
+		f := g.fighters[1]
+		if fighterSnapshot.ID == g.playerID {
+			f = g.fighters[0]
+		}
+		if char, exists := g.characters[fighterSnapshot.CharacterName]; exists && f.Character != char {
+			f.Character = char
+		}
+		f.ApplySnapshot(fighterSnapshot)
+	}
+}
+
+func (g *Game) reconcile(snapshot message.GameSnapshot) {
+	state, ok := g.session.StateAt(snapshot.Tick)
+	if !ok {
+		return
+	}
+
+	current := g.match.SaveState()
+	g.match.LoadState(state)
+	if match.SameSnapshot(g.match.Snapshot(), snapshot) {
+		g.match.LoadState(current)
+		return
+	}
+
+	jsfunc.LogWarn(fmt.Sprintf("State diverged from the server at tick %d, resyncing", snapshot.Tick))
+	g.match.ApplySnapshot(snapshot)
+	g.session.Resync(snapshot.Tick, g.match.SaveState())
 }
 
 func (g *Game) sendEndGameMsg() {
@@ -392,11 +647,15 @@ func (g *Game) sendEndGameMsg() {
 	g.sendMessage(msg)
 }
 
-func (g *Game) sendMessage(msg message.Message) {
-	jsonData, err := json.Marshal(msg)
-	if err != nil {
-		jsfunc.LogError(fmt.Sprint("JSON error:", err.Error()))
-		return
+func (g *Game) sendLeaveRoomMsg() {
+	msg := message.Message{
+		Type: message.LeaveRoomMsg,
+		Data: nil,
 	}
-	g.socket.Call("send", string(jsonData))
+
+	g.sendMessage(msg)
+}
+
+func (g *Game) sendMessage(msg message.Message) {
+	g.send(msg)
 }
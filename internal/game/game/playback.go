@@ -0,0 +1,196 @@
+//go:build js
+
+package game
+
+import (
+	"fmt"
+	"math"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/replay"
+)
+
+const (
+	MinPlaybackSpeed = 0.25
+	MaxPlaybackSpeed = 4
+
+	playbackSnapshotInterval = 60
+	playbackUiInterval       = 6
+)
+
+type playback struct {
+	replay        replay.Replay
+	paused        bool
+	speed         float64
+	showColliders bool
+	snapshots     map[int]interface{}
+}
+
+func (g *Game) StartReplay(r replay.Replay) error {
+	if r.FrameCount() == 0 {
+		return fmt.Errorf("replay has no frames")
+	}
+
+	characterName := ""
+	if len(r.Header.Characters) > 0 {
+		characterName = r.Header.Characters[0]
+	}
+
+	g.Stop()
+	if _, err := g.setupMatch("", r.Header.StartGame, characterName); err != nil {
+		return err
+	}
+
+	g.playback = &playback{
+		replay:    r,
+		speed:     1,
+		snapshots: make(map[int]interface{}),
+	}
+	g.savePrevHitBoxes()
+	g.updatePlaybackUi()
+	jsfunc.ShowReplayControls(true)
+
+	g.renderLoop()
+	return nil
+}
+
+func (g *Game) StopReplay() {
+	if g.playback == nil {
+		return
+	}
+
+	g.Stop()
+	jsfunc.ShowScreen(jsfunc.MainMenuScreen)
+}
+
+func (g *Game) TogglePause() {
+	if g.playback == nil {
+		return
+	}
+
+	if g.playback.paused && g.playbackEnded() {
+		g.SeekReplay(0)
+	}
+	g.playback.paused = !g.playback.paused
+	g.updatePlaybackUi()
+}
+
+func (g *Game) StepReplay() {
+	if g.playback == nil {
+		return
+	}
+
+	g.playback.paused = true
+	g.savePrevHitBoxes()
+	g.stepPlayback()
+	g.updatePlaybackUi()
+}
+
+func (g *Game) SetReplaySpeed(speed float64) {
+	if g.playback == nil {
+		return
+	}
+
+	g.playback.speed = math.Min(math.Max(speed, MinPlaybackSpeed), MaxPlaybackSpeed)
+	g.updatePlaybackUi()
+}
+
+func (g *Game) ToggleColliders(show bool) {
+	if g.playback == nil {
+		return
+	}
+
+	g.playback.showColliders = show
+}
+
+func (g *Game) SeekReplay(frame int) {
+	if g.playback == nil {
+		return
+	}
+
+	if frame < 0 {
+		frame = 0
+	}
+	if frame > g.playback.replay.FrameCount() {
+		frame = g.playback.replay.FrameCount()
+	}
+
+	if frame < g.playbackFrame() {
+		from := -1
+		for snapshotFrame := range g.playback.snapshots {
+			if snapshotFrame <= frame && snapshotFrame > from {
+				from = snapshotFrame
+			}
+		}
+		g.match.LoadState(g.playback.snapshots[from])
+	}
+
+	for g.playbackFrame() < frame {
+		if !g.stepPlayback() {
+			break
+		}
+	}
+
+	g.savePrevHitBoxes()
+	g.updatePlaybackUi()
+}
+
+func (g *Game) timeScale() float64 {
+	if g.playback == nil {
+		return 1
+	}
+	return g.playback.speed
+}
+
+func (g *Game) updatePlayback() {
+	if g.escapePressed() {
+		g.StopReplay()
+		return
+	}
+
+	g.savePrevHitBoxes()
+	if g.playback.paused {
+		return
+	}
+
+	if !g.stepPlayback() {
+		g.playback.paused = true
+		g.updatePlaybackUi()
+		return
+	}
+
+	if g.playbackFrame()%playbackUiInterval == 0 {
+		g.updatePlaybackUi()
+	}
+}
+
+func (g *Game) stepPlayback() bool {
+	if g.playbackEnded() {
+		return false
+	}
+
+	frame := g.playbackFrame()
+	if frame%playbackSnapshotInterval == 0 {
+		if _, exists := g.playback.snapshots[frame]; !exists {
+			g.playback.snapshots[frame] = g.match.SaveState()
+		}
+	}
+
+	g.match.Advance(g.playback.replay.Inputs[frame])
+	g.gameState.isStart = g.match.IsStart()
+	g.gameState.isEnd = g.match.IsEnd()
+	g.syncRounds(g.match.Round(), g.match.Wins())
+
+	return true
+}
+
+func (g *Game) playbackFrame() int {
+	return int(g.match.Tick())
+}
+
+func (g *Game) playbackEnded() bool {
+	return g.playbackFrame() >= g.playback.replay.FrameCount() || g.match.IsOver()
+}
+
+func (g *Game) updatePlaybackUi() {
+	jsfunc.UpdateReplayControls(g.playbackFrame(), g.playback.replay.FrameCount(), g.playback.paused, g.playback.speed)
+}
@@ -0,0 +1,142 @@
+//go:build js
+
+package game
+
+import (
+	"fmt"
+	"webgl-app/internal/graphics/primitives"
+	"webgl-app/internal/graphics/webgl"
+	"webgl-app/internal/jsfunc"
+)
+
+const (
+	roundPipSize    = 24
+	roundPipSpacing = 32
+	roundPipTop     = 95
+	roundCaptionTop = 450
+)
+
+type rounds struct {
+	round       int
+	wins        []int
+	roundsToWin int
+	caption     *webgl.Sprite
+	captionText string
+}
+
+func (g *Game) createRoundPips() {
+	for name, won := range map[string]bool{"round_pip": false, "round_pip_won": true} {
+		tex := webgl.NewTexture(g.glCtx, jsfunc.CreatePipImage(roundPipSize, won))
+		if tex == nil {
+			continue
+		}
+		g.titles[name] = webgl.NewSprite(tex, &primitives.Rect{Pos: primitives.NewVec2(0, 0), Size: primitives.NewVec2(tex.Width, tex.Height)}, 1, nil, nil)
+	}
+}
+
+func (g *Game) resetRounds(bestOf int) {
+	if bestOf < 1 {
+		bestOf = 1
+	}
+
+	g.deleteRoundCaption()
+	g.rounds = rounds{
+		round:       1,
+		wins:        make([]int, 2),
+		roundsToWin: bestOf/2 + 1,
+	}
+}
+
+func (g *Game) syncRounds(round int, wins []int) {
+	g.rounds.round = round
+	if len(wins) == len(g.rounds.wins) {
+		copy(g.rounds.wins, wins)
+		if g.swapped {
+			g.rounds.wins[0], g.rounds.wins[1] = g.rounds.wins[1], g.rounds.wins[0]
+		}
+	}
+
+	text := ""
+	if g.gameState.isEnd {
+		text = g.roundCaptionText()
+	}
+	if text != g.rounds.captionText {
+		g.updateRoundCaption(text)
+	}
+}
+
+func (g *Game) roundCaptionText() string {
+	if g.rounds.roundsToWin <= 1 {
+		return ""
+	}
+
+	score := fmt.Sprintf("%d - %d", g.rounds.wins[0], g.rounds.wins[1])
+	if g.matchDecided() {
+		return "Match " + score
+	}
+	return fmt.Sprintf("Round %d  ·  %s", g.rounds.round, score)
+}
+
+func (g *Game) matchDecided() bool {
+	for _, wins := range g.rounds.wins {
+		if wins >= g.rounds.roundsToWin {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) updateRoundCaption(text string) {
+	g.deleteRoundCaption()
+	g.rounds.captionText = text
+	if text == "" {
+		return
+	}
+
+	tex := webgl.NewTexture(g.glCtx, jsfunc.CreateTextImage(text))
+	if tex == nil {
+		return
+	}
+	g.rounds.caption = webgl.NewSprite(tex, &primitives.Rect{Pos: primitives.NewVec2(0, 0), Size: primitives.NewVec2(tex.Width, tex.Height)}, 1.5, nil, nil)
+}
+
+func (g *Game) deleteRoundCaption() {
+	if g.rounds.caption != nil {
+		g.rounds.caption.Texture.Delete(g.glCtx)
+	}
+	g.rounds.caption = nil
+	g.rounds.captionText = ""
+}
+
+func (g *Game) roundPipsDraw() {
+	if g.rounds.roundsToWin <= 1 {
+		return
+	}
+
+	for i := 0; i < g.rounds.roundsToWin; i++ {
+		left, right := g.titles["round_pip"], g.titles["round_pip"]
+		if i < g.rounds.wins[0] {
+			left = g.titles["round_pip_won"]
+		}
+		if i < g.rounds.wins[1] {
+			right = g.titles["round_pip_won"]
+		}
+
+		offset := float64(i * roundPipSpacing)
+		if left != nil {
+			g.glCtx.RenderSprite(left, primitives.NewRect(50+offset, roundPipTop, 0, 0), false)
+		}
+		if right != nil {
+			g.glCtx.RenderSprite(right, primitives.NewRect(1550-roundPipSize-offset, roundPipTop, 0, 0), false)
+		}
+	}
+}
+
+func (g *Game) roundCaptionDraw() {
+	caption := g.rounds.caption
+	if caption == nil {
+		return
+	}
+
+	g.glCtx.RenderSprite(caption, primitives.NewRect(800-caption.Rect.Width()*caption.Scale/2, roundCaptionTop, 0, 0), false)
+}
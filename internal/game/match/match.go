@@ -0,0 +1,426 @@
+package match
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"webgl-app/internal/config"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/game/fighter"
+	"webgl-app/internal/net/message"
+)
+
+const TickRate = 60
+
+const TickDuration = time.Second / TickRate
+
+const SnapshotInterval = 10
+
+const (
+	DefaultStartCooldown = 2 * time.Second
+	RoundEndCooldown     = 3 * time.Second
+)
+
+var Levels = []string{"level_1", "level_2"}
+
+type State struct {
+	fighters      []fighter.Fighter
+	tick          uint64
+	round         int
+	wins          []int
+	roundWinner   int
+	isStart       bool
+	isEnd         bool
+	isOver        bool
+	startCooldown float64
+	endCooldown   float64
+}
+
+type Match struct {
+	fighters      []*fighter.Fighter
+	ids           []string
+	char          *character.Character
+	positions     []float64
+	tick          uint64
+	round         int
+	wins          []int
+	roundWinner   int
+	roundsToWin   int
+	isStart       bool
+	isEnd         bool
+	isOver        bool
+	startDelay    float64
+	startCooldown float64
+	endCooldown   float64
+	mu            sync.Mutex
+}
+
+func NewMatch(char *character.Character, fightersPositions map[string]int) (*Match, error) {
+	if char == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+
+	positions := []float64{
+		config.ProgramConfig.Window.Width / 4,
+		config.ProgramConfig.Window.Width - config.ProgramConfig.Window.Width/4,
+	}
+
+	m := &Match{
+		fighters:      make([]*fighter.Fighter, len(positions)),
+		ids:           make([]string, len(positions)),
+		char:          char,
+		positions:     positions,
+		round:         1,
+		wins:          make([]int, len(positions)),
+		roundWinner:   -1,
+		roundsToWin:   1,
+		startDelay:    DefaultStartCooldown.Seconds(),
+		startCooldown: DefaultStartCooldown.Seconds(),
+		endCooldown:   RoundEndCooldown.Seconds(),
+	}
+
+	for id, fighterPos := range fightersPositions {
+		if fighterPos < 0 || fighterPos >= len(positions) {
+			return nil, fmt.Errorf("invalid fighter position")
+		}
+		m.ids[fighterPos] = id
+		m.fighters[fighterPos] = fighter.NewFighter(char, positions[fighterPos])
+	}
+
+	for i := range m.fighters {
+		if m.fighters[i] == nil {
+			m.fighters[i] = fighter.NewFighter(char, positions[i])
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Match) Slot(playerID string) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, id := range m.ids {
+		if id != "" && id == playerID {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (m *Match) Players() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, len(m.ids))
+	copy(ids, m.ids)
+
+	return ids
+}
+
+func (m *Match) Fighters() []*fighter.Fighter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.fighters
+}
+
+func (m *Match) Advance(controls []message.FighterControl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, f := range m.fighters {
+		if i < len(controls) {
+			f.Control = controls[i]
+		} else {
+			f.Control = message.FighterControl{}
+		}
+	}
+
+	m.step(TickDuration)
+}
+
+func (m *Match) step(deltaTime time.Duration) {
+	if m.isOver {
+		return
+	}
+
+	m.tick++
+
+	if !m.isStart {
+		m.startCooldown -= deltaTime.Seconds()
+		if m.startCooldown <= 0 {
+			m.isStart = true
+		}
+	}
+	if m.isEnd {
+		m.endCooldown -= deltaTime.Seconds()
+		if m.endCooldown <= 0 {
+			if m.decided() {
+				m.isOver = true
+				return
+			}
+			m.nextRound()
+		}
+	}
+
+	if !m.isStart {
+		for _, f := range m.fighters {
+			f.Control = message.FighterControl{}
+		}
+	}
+
+	m.fighters[0].Update(deltaTime, m.fighters[1])
+	m.fighters[1].Update(deltaTime, m.fighters[0])
+
+	if !m.isEnd && (m.fighters[0].State == fighter.Death || m.fighters[1].State == fighter.Death) {
+		m.isEnd = true
+		m.endRound()
+	}
+}
+
+func (m *Match) endRound() {
+	switch {
+	case m.fighters[0].State == fighter.Death && m.fighters[1].State == fighter.Death:
+		m.roundWinner = -1
+	case m.fighters[1].State == fighter.Death:
+		m.roundWinner = 0
+	default:
+		m.roundWinner = 1
+	}
+
+	if m.roundWinner >= 0 {
+		m.wins[m.roundWinner]++
+	}
+}
+
+func (m *Match) nextRound() {
+	for i := range m.fighters {
+		*m.fighters[i] = *fighter.NewFighter(m.char, m.positions[i])
+	}
+
+	m.round++
+	m.roundWinner = -1
+	m.isStart = false
+	m.isEnd = false
+	m.startCooldown = m.startDelay
+	m.endCooldown = RoundEndCooldown.Seconds()
+}
+
+func (m *Match) decided() bool {
+	for _, wins := range m.wins {
+		if wins >= m.roundsToWin {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Match) SaveState() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fighters := make([]fighter.Fighter, len(m.fighters))
+	for i, f := range m.fighters {
+		fighters[i] = *f
+	}
+
+	wins := make([]int, len(m.wins))
+	copy(wins, m.wins)
+
+	return State{
+		fighters:      fighters,
+		tick:          m.tick,
+		round:         m.round,
+		wins:          wins,
+		roundWinner:   m.roundWinner,
+		isStart:       m.isStart,
+		isEnd:         m.isEnd,
+		isOver:        m.isOver,
+		startCooldown: m.startCooldown,
+		endCooldown:   m.endCooldown,
+	}
+}
+
+func (m *Match) LoadState(state interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := state.(State)
+	if !ok {
+		return
+	}
+
+	for i := range m.fighters {
+		*m.fighters[i] = s.fighters[i]
+	}
+	m.tick = s.tick
+	m.round = s.round
+	copy(m.wins, s.wins)
+	m.roundWinner = s.roundWinner
+	m.isStart = s.isStart
+	m.isEnd = s.isEnd
+	m.isOver = s.isOver
+	m.startCooldown = s.startCooldown
+	m.endCooldown = s.endCooldown
+}
+
+func (m *Match) SetStartCooldown(cooldown time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.startDelay = cooldown.Seconds()
+	m.startCooldown = cooldown.Seconds()
+}
+
+func (m *Match) SetBestOf(bestOf int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.roundsToWin = bestOf/2 + 1
+}
+
+func (m *Match) Round() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.round
+}
+
+func (m *Match) RoundsToWin() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.roundsToWin
+}
+
+func (m *Match) Wins() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wins := make([]int, len(m.wins))
+	copy(wins, m.wins)
+
+	return wins
+}
+
+func (m *Match) RoundWinner() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isEnd || m.roundWinner < 0 {
+		return ""
+	}
+	return m.ids[m.roundWinner]
+}
+
+func (m *Match) IsDecided() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isEnd && m.decided()
+}
+
+func (m *Match) Tick() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tick
+}
+
+func (m *Match) IsStart() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isStart
+}
+
+func (m *Match) IsEnd() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isEnd
+}
+
+func (m *Match) Snapshot() message.GameSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fighters := make([]message.FighterSnapshot, len(m.fighters))
+	for i, f := range m.fighters {
+		fighters[i] = f.Snapshot(m.ids[i])
+	}
+
+	wins := make([]int, len(m.wins))
+	copy(wins, m.wins)
+
+	return message.GameSnapshot{
+		Tick:     m.tick,
+		Round:    m.round,
+		Wins:     wins,
+		IsStart:  m.isStart,
+		IsEnd:    m.isEnd,
+		Fighters: fighters,
+	}
+}
+
+func (m *Match) ApplySnapshot(snapshot message.GameSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tick = snapshot.Tick
+	m.round = snapshot.Round
+	copy(m.wins, snapshot.Wins)
+	m.isStart = snapshot.IsStart
+	m.isEnd = snapshot.IsEnd
+
+	for _, fighterSnapshot := range snapshot.Fighters {
+		for i, id := range m.ids {
+			if id == fighterSnapshot.ID {
+				m.fighters[i].ApplySnapshot(fighterSnapshot)
+			}
+		}
+	}
+}
+
+func SameSnapshot(a, b message.GameSnapshot) bool {
+	if a.Tick != b.Tick || a.Round != b.Round || a.IsStart != b.IsStart || a.IsEnd != b.IsEnd {
+		return false
+	}
+	if len(a.Wins) != len(b.Wins) || len(a.Fighters) != len(b.Fighters) {
+		return false
+	}
+	for i := range a.Wins {
+		if a.Wins[i] != b.Wins[i] {
+			return false
+		}
+	}
+	for i := range a.Fighters {
+		if !fighter.SameSnapshot(a.Fighters[i], b.Fighters[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *Match) IsOver() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isOver
+}
+
+func (m *Match) Winner() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, wins := range m.wins {
+		if wins >= m.roundsToWin {
+			return m.ids[i]
+		}
+	}
+
+	return ""
+}
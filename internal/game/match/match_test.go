@@ -0,0 +1,63 @@
+package match
+
+import (
+	"path/filepath"
+	"testing"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/net/message"
+)
+
+func newTestMatch(t *testing.T) *Match {
+	t.Helper()
+
+	chars, err := character.LoadCharacters(filepath.Join("..", "..", "..", "assets", "meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, char := range chars {
+		m, err := NewMatch(char, map[string]int{"first": 0, "second": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.SetStartCooldown(0)
+		return m
+	}
+
+	t.Fatal("no characters loaded")
+	return nil
+}
+
+func TestSameSnapshotToleratesRounding(t *testing.T) {
+	m := newTestMatch(t)
+	m.Advance([]message.FighterControl{{MoveRight: true}, {}})
+
+	snapshot := m.Snapshot()
+	rounded := m.Snapshot()
+	rounded.Fighters[0].HitBox.Pos.X = float64(float32(rounded.Fighters[0].HitBox.Pos.X + 0.001))
+	if !SameSnapshot(snapshot, rounded) {
+		t.Fatal("float32 rounding counted as divergence")
+	}
+
+	rounded.Fighters[1].HealthPoints -= 5
+	if SameSnapshot(snapshot, rounded) {
+		t.Fatal("health difference was not detected")
+	}
+}
+
+func TestApplySnapshotLoadsServerState(t *testing.T) {
+	server := newTestMatch(t)
+	client := newTestMatch(t)
+
+	for i := 0; i < 30; i++ {
+		server.Advance([]message.FighterControl{{MoveRight: true}, {}})
+		client.Advance([]message.FighterControl{{MoveLeft: true}, {}})
+	}
+	if SameSnapshot(server.Snapshot(), client.Snapshot()) {
+		t.Fatal("different inputs produced the same state")
+	}
+
+	client.ApplySnapshot(server.Snapshot())
+	if !SameSnapshot(server.Snapshot(), client.Snapshot()) {
+		t.Fatalf("client state differs after ApplySnapshot:\n%+v\n%+v", client.Snapshot(), server.Snapshot())
+	}
+}
@@ -0,0 +1,112 @@
+package rollback
+
+import (
+	"fmt"
+	"sync"
+	"webgl-app/internal/net/message"
+)
+
+const maxInputsAhead = 120
+
+type InputQueue struct {
+	active []bool
+	inputs []map[uint64]message.FighterControl
+	last   []message.FighterControl
+	frame  uint64
+	mu     sync.Mutex
+}
+
+func NewInputQueue(active []bool, inputDelay int) *InputQueue {
+	q := &InputQueue{
+		active: active,
+		inputs: make([]map[uint64]message.FighterControl, len(active)),
+		last:   make([]message.FighterControl, len(active)),
+	}
+
+	for i := range q.inputs {
+		q.inputs[i] = make(map[uint64]message.FighterControl)
+		for f := 0; f < inputDelay; f++ {
+			q.inputs[i][uint64(f)] = message.FighterControl{}
+		}
+	}
+
+	return q
+}
+
+func (q *InputQueue) Add(player int, frame uint64, control message.FighterControl) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if player < 0 || player >= len(q.inputs) || !q.active[player] {
+		return fmt.Errorf("invalid player slot")
+	}
+	if frame < q.frame {
+		return fmt.Errorf("input is too late")
+	}
+	if frame >= q.frame+maxInputsAhead {
+		return fmt.Errorf("input is too far ahead")
+	}
+
+	q.inputs[player][frame] = control
+
+	return nil
+}
+
+func (q *InputQueue) SetActive(player int, active bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if player < 0 || player >= len(q.active) {
+		return
+	}
+
+	q.active[player] = active
+	if !active {
+		q.last[player] = message.FighterControl{}
+		q.inputs[player] = make(map[uint64]message.FighterControl)
+	}
+}
+
+func (q *InputQueue) Frame() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.frame
+}
+
+func (q *InputQueue) Ready() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, inputs := range q.inputs {
+		if !q.active[i] {
+			continue
+		}
+		if _, exists := inputs[q.frame]; !exists {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *InputQueue) Confirm() (uint64, []message.FighterControl) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	frame := q.frame
+	controls := make([]message.FighterControl, len(q.inputs))
+
+	for i, inputs := range q.inputs {
+		if !q.active[i] {
+			q.last[i] = message.FighterControl{}
+		} else if control, exists := inputs[frame]; exists {
+			q.last[i] = control
+			delete(inputs, frame)
+		}
+		controls[i] = q.last[i]
+	}
+	q.frame++
+
+	return frame, controls
+}
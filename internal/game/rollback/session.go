@@ -0,0 +1,209 @@
+package rollback
+
+import (
+	"webgl-app/internal/net/message"
+)
+
+type Simulation interface {
+	Advance(controls []message.FighterControl)
+	SaveState() interface{}
+	LoadState(state interface{})
+}
+
+type Session struct {
+	sim            Simulation
+	players        int
+	localPlayer    int
+	inputDelay     int
+	maxRollback    int
+	frame          uint64
+	confirmedFrame uint64
+	confirmed      map[uint64][]message.FighterControl
+	local          map[uint64]message.FighterControl
+	used           map[uint64][]message.FighterControl
+	states         map[uint64]interface{}
+	needRollback   bool
+	rollbackFrame  uint64
+}
+
+func NewSession(sim Simulation, players, localPlayer, inputDelay, maxRollback int) *Session {
+	s := &Session{
+		sim:         sim,
+		players:     players,
+		localPlayer: localPlayer,
+		inputDelay:  inputDelay,
+		maxRollback: maxRollback,
+		confirmed:   make(map[uint64][]message.FighterControl),
+		local:       make(map[uint64]message.FighterControl),
+		used:        make(map[uint64][]message.FighterControl),
+		states:      make(map[uint64]interface{}),
+	}
+
+	for f := 0; f < inputDelay; f++ {
+		s.local[uint64(f)] = message.FighterControl{}
+	}
+
+	return s
+}
+
+func (s *Session) Frame() uint64 {
+	return s.frame
+}
+
+func (s *Session) ConfirmedFrame() uint64 {
+	return s.confirmedFrame
+}
+
+func (s *Session) CanAdvance() bool {
+	return s.frame < s.confirmedFrame+uint64(s.maxRollback)
+}
+
+func (s *Session) AddLocalInput(control message.FighterControl) uint64 {
+	frame := s.frame + uint64(s.inputDelay)
+	s.local[frame] = control
+
+	return frame
+}
+
+func (s *Session) Confirm(frame uint64, controls []message.FighterControl) {
+	if frame != s.confirmedFrame {
+		return
+	}
+
+	inputs := make([]message.FighterControl, s.players)
+	copy(inputs, controls)
+	s.confirmed[frame] = inputs
+	s.confirmedFrame++
+
+	used, simulated := s.used[frame]
+	if simulated && !sameControls(used, inputs) {
+		if !s.needRollback || frame < s.rollbackFrame {
+			s.rollbackFrame = frame
+		}
+		s.needRollback = true
+	}
+}
+
+func (s *Session) Advance() bool {
+	if s.needRollback {
+		s.rollback()
+	}
+
+	if !s.CanAdvance() {
+		return false
+	}
+
+	s.step(s.frame)
+	s.frame++
+	s.prune()
+
+	return true
+}
+
+func (s *Session) step(frame uint64) {
+	inputs := s.inputsFor(frame)
+
+	s.states[frame] = s.sim.SaveState()
+	s.sim.Advance(inputs)
+	s.used[frame] = inputs
+}
+
+func (s *Session) StateAt(frame uint64) (interface{}, bool) {
+	if frame > s.confirmedFrame || frame > s.frame {
+		return nil, false
+	}
+
+	if s.needRollback {
+		s.rollback()
+	}
+	if frame == s.frame {
+		return s.sim.SaveState(), true
+	}
+
+	state, exists := s.states[frame]
+	return state, exists
+}
+
+func (s *Session) Resync(frame uint64, state interface{}) {
+	if frame > s.frame {
+		return
+	}
+
+	s.needRollback = false
+	s.sim.LoadState(state)
+	for f := frame; f < s.frame; f++ {
+		s.step(f)
+	}
+}
+
+func (s *Session) rollback() {
+	s.needRollback = false
+
+	state, exists := s.states[s.rollbackFrame]
+	if !exists {
+		return
+	}
+
+	s.sim.LoadState(state)
+	for f := s.rollbackFrame; f < s.frame; f++ {
+		s.step(f)
+	}
+}
+
+func (s *Session) inputsFor(frame uint64) []message.FighterControl {
+	if inputs, exists := s.confirmed[frame]; exists {
+		return inputs
+	}
+
+	inputs := make([]message.FighterControl, s.players)
+	if s.confirmedFrame > 0 {
+		if last, exists := s.confirmed[s.confirmedFrame-1]; exists {
+			copy(inputs, last)
+		}
+	}
+	if control, exists := s.local[frame]; exists && s.localPlayer >= 0 && s.localPlayer < s.players {
+		inputs[s.localPlayer] = control
+	}
+
+	return inputs
+}
+
+func (s *Session) prune() {
+	if s.confirmedFrame < 2 || s.needRollback {
+		return
+	}
+
+	keep := s.confirmedFrame - 1
+	for f := range s.states {
+		if f < keep {
+			delete(s.states, f)
+		}
+	}
+	for f := range s.used {
+		if f < keep {
+			delete(s.used, f)
+		}
+	}
+	for f := range s.confirmed {
+		if f < keep {
+			delete(s.confirmed, f)
+		}
+	}
+	for f := range s.local {
+		if f < keep {
+			delete(s.local, f)
+		}
+	}
+}
+
+func sameControls(a, b []message.FighterControl) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package rollback
+
+import (
+	"testing"
+	"webgl-app/internal/net/message"
+)
+
+type counterSim struct {
+	value int
+}
+
+func (c *counterSim) Advance(controls []message.FighterControl) {
+	for _, control := range controls {
+		if control.Attack {
+			c.value += 10
+		}
+	}
+	c.value++
+}
+
+func (c *counterSim) SaveState() interface{} {
+	return c.value
+}
+
+func (c *counterSim) LoadState(state interface{}) {
+	c.value = state.(int)
+}
+
+func TestStateAtResolvesPendingRollback(t *testing.T) {
+	sim := &counterSim{}
+	s := NewSession(sim, 2, 0, 0, 8)
+
+	for i := 0; i < 3; i++ {
+		s.Advance()
+	}
+	s.Confirm(0, []message.FighterControl{{}, {Attack: true}})
+
+	state, ok := s.StateAt(1)
+	if !ok || state.(int) != 11 {
+		t.Fatalf("StateAt(1) = %v, %v, want 11", state, ok)
+	}
+	if sim.value != 33 {
+		t.Fatalf("current value is %d, want 33 after the rollback", sim.value)
+	}
+
+	if _, ok := s.StateAt(2); ok {
+		t.Fatal("StateAt returned a state for an unconfirmed frame")
+	}
+}
+
+func TestResyncResimulatesFromFrame(t *testing.T) {
+	sim := &counterSim{}
+	s := NewSession(sim, 2, 0, 0, 8)
+
+	s.Confirm(0, []message.FighterControl{{}, {}})
+	s.Confirm(1, []message.FighterControl{{}, {}})
+	for i := 0; i < 3; i++ {
+		s.Advance()
+	}
+
+	s.Resync(1, 100)
+	if sim.value != 102 {
+		t.Fatalf("value is %d, want 102", sim.value)
+	}
+	if state, ok := s.StateAt(1); !ok || state.(int) != 100 {
+		t.Fatalf("StateAt(1) = %v, %v, want 100", state, ok)
+	}
+}
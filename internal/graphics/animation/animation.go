@@ -11,50 +11,6 @@ import (
 	"webgl-app/internal/utils"
 )
 
-const (
-	TypeAnimationsSpritesheet string = "animation-spritesheet"
-	TypeAnimationsSet         string = "animation-set"
-)
-
-type AnimationsSpritesheetCutArea struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
-}
-
-type AnimationTextureData struct {
-	Texture          string `json:"texture"`
-	FrameWidthCount  int    `json:"frame_width_count"`
-	FrameHeigntCount int    `json:"frame_height_count"`
-	AllFrameCount    int    `json:"all_frame_count"`
-}
-
-type AnimationsSpritesheetData struct {
-	SpritesheetTexture string                       `json:"spritesheet_texture"`
-	FrameWidthCount    int                          `json:"frame_width_count"`
-	FrameHeigntCount   int                          `json:"frame_height_count"`
-	AllFrameCount      int                          `json:"all_frame_count"`
-	CutArea            AnimationsSpritesheetCutArea `json:"cut_area"`
-}
-
-type AnimationsParameters struct {
-	Type            string                    `json:"type"`
-	SpritesheetData AnimationsSpritesheetData `json:"spritesheet_data"`
-}
-
-type AnimationData struct {
-	TextureData AnimationTextureData `json:"texture_data"`
-	FrameTime   float64              `json:"frame_time"`
-	FirstFrame  int                  `json:"first_frame"`
-	FrameCount  int                  `json:"frame_count"`
-}
-
-type AnimationsMeta struct {
-	Parameters AnimationsParameters     `json:"parameters"`
-	Animations map[string]AnimationData `json:"animations"`
-}
-
 type CreateAnimationInfo struct {
 	Texture         *webgl.Texture
 	AnimationData   AnimationData
@@ -65,11 +21,8 @@ type CreateAnimationInfo struct {
 }
 
 type Animation struct {
-	Frames            []*webgl.Sprite
-	FrameTime         float64
-	CurrentFrameIndex int
-	IsEnd             bool
-	timer             float64
+	Clip
+	Frames []*webgl.Sprite
 }
 
 func NewAnimation(info CreateAnimationInfo) *Animation {
@@ -104,10 +57,8 @@ func NewAnimation(info CreateAnimationInfo) *Animation {
 	}
 
 	return &Animation{
-		Frames:            frames,
-		FrameTime:         aData.FrameTime,
-		CurrentFrameIndex: 0,
-		timer:             0,
+		Clip:   NewClip(aData.FrameTime, len(frames)),
+		Frames: frames,
 	}
 }
 
@@ -151,14 +102,6 @@ func NewAnimationsSet(metaName string, assets *assetsmanager.AssetsManager, scal
 	return animations, nil
 }
 
-func (a *Animation) Reset() {
-	if a == nil {
-		return
-	}
-	a.timer = 0
-	a.CurrentFrameIndex = 0
-}
-
 func (a *Animation) Update(deltaTime float64) {
 	if a == nil {
 		jsfunc.LogError("Animation.Update: nil animation")
@@ -170,14 +113,7 @@ func (a *Animation) Update(deltaTime float64) {
 		return
 	}
 
-	a.timer += deltaTime
-	if a.timer > a.FrameTime {
-		a.timer = 0
-		a.CurrentFrameIndex = (a.CurrentFrameIndex + 1) % len(a.Frames)
-		if a.CurrentFrameIndex == len(a.Frames)-1 {
-			a.IsEnd = true
-		}
-	}
+	a.Clip.Update(deltaTime)
 }
 
 func (a *Animation) GetCurrentFrame() *webgl.Sprite {
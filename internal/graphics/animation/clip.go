@@ -0,0 +1,39 @@
+package animation
+
+type Clip struct {
+	FrameTime         float64
+	FrameCount        int
+	CurrentFrameIndex int
+	IsEnd             bool
+	timer             float64
+}
+
+func NewClip(frameTime float64, frameCount int) Clip {
+	return Clip{
+		FrameTime:  frameTime,
+		FrameCount: frameCount,
+	}
+}
+
+func (c *Clip) Reset() {
+	if c == nil {
+		return
+	}
+	c.timer = 0
+	c.CurrentFrameIndex = 0
+}
+
+func (c *Clip) Update(deltaTime float64) {
+	if c == nil || c.FrameCount == 0 {
+		return
+	}
+
+	c.timer += deltaTime
+	if c.timer > c.FrameTime {
+		c.timer = 0
+		c.CurrentFrameIndex = (c.CurrentFrameIndex + 1) % c.FrameCount
+		if c.CurrentFrameIndex == c.FrameCount-1 {
+			c.IsEnd = true
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package animation
+
+const (
+	TypeAnimationsSpritesheet string = "animation-spritesheet"
+	TypeAnimationsSet         string = "animation-set"
+)
+
+type AnimationsSpritesheetCutArea struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type AnimationTextureData struct {
+	Texture          string `json:"texture"`
+	FrameWidthCount  int    `json:"frame_width_count"`
+	FrameHeigntCount int    `json:"frame_height_count"`
+	AllFrameCount    int    `json:"all_frame_count"`
+}
+
+type AnimationsSpritesheetData struct {
+	SpritesheetTexture string                       `json:"spritesheet_texture"`
+	FrameWidthCount    int                          `json:"frame_width_count"`
+	FrameHeigntCount   int                          `json:"frame_height_count"`
+	AllFrameCount      int                          `json:"all_frame_count"`
+	CutArea            AnimationsSpritesheetCutArea `json:"cut_area"`
+}
+
+type AnimationsParameters struct {
+	Type            string                    `json:"type"`
+	SpritesheetData AnimationsSpritesheetData `json:"spritesheet_data"`
+}
+
+type AnimationData struct {
+	TextureData AnimationTextureData `json:"texture_data"`
+	FrameTime   float64              `json:"frame_time"`
+	FirstFrame  int                  `json:"first_frame"`
+	FrameCount  int                  `json:"frame_count"`
+}
+
+type AnimationsMeta struct {
+	Parameters AnimationsParameters     `json:"parameters"`
+	Animations map[string]AnimationData `json:"animations"`
+}
+
+func NewClipsSet(meta AnimationsMeta) map[string]Clip {
+	clips := make(map[string]Clip)
+
+	for aName, aData := range meta.Animations {
+		frameCount := aData.FrameCount
+		if last := aData.FirstFrame + aData.FrameCount - 1; last > meta.Parameters.SpritesheetData.AllFrameCount {
+			frameCount -= last - meta.Parameters.SpritesheetData.AllFrameCount
+		}
+		if frameCount < 0 {
+			frameCount = 0
+		}
+		clips[aName] = NewClip(aData.FrameTime, frameCount)
+	}
+
+	return clips
+}
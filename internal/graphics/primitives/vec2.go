@@ -95,3 +95,10 @@ func (v *Vec2) Normalize() Vec2 {
 	}
 	return Vec2{}
 }
+
+func (v *Vec2) Lerp(other Vec2, t float64) Vec2 {
+	return Vec2{
+		X: v.X + (other.X-v.X)*t,
+		Y: v.Y + (other.Y-v.Y)*t,
+	}
+}
@@ -71,29 +71,27 @@ func (ctx *GLContext) InitWebGL() error {
 	ctx.textureQueue = newTextureQueue(textureProgram)
 	jsfunc.LogInfo("Texture queue created")
 
-	if config.ProgramConfig.Debug {
-		jsfunc.SetLoadingProgress(4, "Loading debug shaders...")
-		debugVertSrc, debugFragSrc, err := ctx.loadShaders(shadersSources.DebugShaders)
-		if err != nil {
-			return fmt.Errorf("failed to load debug shaders: %v", err)
-		}
-		jsfunc.LogInfo("Debug shaders loaded")
+	jsfunc.SetLoadingProgress(4, "Loading debug shaders...")
+	debugVertSrc, debugFragSrc, err := ctx.loadShaders(shadersSources.DebugShaders)
+	if err != nil {
+		return fmt.Errorf("failed to load debug shaders: %v", err)
+	}
+	jsfunc.LogInfo("Debug shaders loaded")
 
-		jsfunc.SetLoadingProgress(6, "Compiling debug shaders...")
-		debugVertShader, debugFragShader, err := ctx.compileShaders(debugVertSrc, debugFragSrc)
-		if err != nil {
-			return fmt.Errorf("debug shaders compilation failed: %v", err)
-		}
-		jsfunc.LogInfo("Debug shaders compiled")
+	jsfunc.SetLoadingProgress(6, "Compiling debug shaders...")
+	debugVertShader, debugFragShader, err := ctx.compileShaders(debugVertSrc, debugFragSrc)
+	if err != nil {
+		return fmt.Errorf("debug shaders compilation failed: %v", err)
+	}
+	jsfunc.LogInfo("Debug shaders compiled")
 
-		jsfunc.SetLoadingProgress(8, "Creating program...")
-		debugProgram, err := ctx.createProgram(debugVertShader, debugFragShader)
-		if err != nil {
-			return fmt.Errorf("debug program creation failed: %v", err)
-		}
-		ctx.debugQueue = newDebugQueue(debugProgram)
-		jsfunc.LogInfo("Debug queue created")
+	jsfunc.SetLoadingProgress(8, "Creating program...")
+	debugProgram, err := ctx.createProgram(debugVertShader, debugFragShader)
+	if err != nil {
+		return fmt.Errorf("debug program creation failed: %v", err)
 	}
+	ctx.debugQueue = newDebugQueue(debugProgram)
+	jsfunc.LogInfo("Debug queue created")
 
 	js.Global().Call("addEventListener", "resize", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		ctx.handleResizeScreen()
@@ -4,7 +4,6 @@ package webgl
 
 import (
 	"syscall/js"
-	"webgl-app/internal/config"
 )
 
 // ----- Texture queue -----
@@ -100,10 +99,6 @@ func (ctx *GLContext) drawTextureQueue() {
 }
 
 func (ctx *GLContext) drawDebugQueue() {
-	if !config.ProgramConfig.Debug {
-		return
-	}
-
 	gl := ctx.GL
 	program := ctx.debugQueue.program
 
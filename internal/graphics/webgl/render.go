@@ -3,7 +3,6 @@
 package webgl
 
 import (
-	"webgl-app/internal/config"
 	"webgl-app/internal/graphics/primitives"
 	"webgl-app/internal/jsfunc"
 )
@@ -71,10 +70,6 @@ func (ctx *GLContext) RenderSprite(sprite *Sprite, drawRect primitives.Rect, spe
 }
 
 func (ctx *GLContext) RenderRect(rect primitives.Rect, color Color) {
-	if !config.ProgramConfig.Debug {
-		return
-	}
-
 	var (
 		x1, y1, x2, y2 float32
 	)
@@ -38,6 +38,10 @@ func NewTexture(glCtx *GLContext, img js.Value) *Texture {
 	}
 }
 
+func (t *Texture) Delete(glCtx *GLContext) {
+	glCtx.GL.Call("deleteTexture", t.texture)
+}
+
 func (t *Texture) GetTexture() *js.Value {
 	return &t.texture
 }
@@ -14,6 +14,8 @@ const (
 	LobbyConnectScreen  Screen = "lobby_connect"
 	LoadingScreenScreen Screen = "loading_screen"
 	GameScreenScreen    Screen = "game_screen"
+	RoomBrowserScreen   Screen = "room_browser"
+	MatchmakingScreen   Screen = "matchmaking"
 )
 
 func ShowScreen(screen Screen) {
@@ -28,6 +30,50 @@ func SwitchStartButtonState(isEnabled bool) {
 	js.Global().Call("switchStartButtonState", isEnabled)
 }
 
+func RenderRoomList(rooms []interface{}, page int, pageCount int) {
+	js.Global().Call("renderRoomList", rooms, page, pageCount)
+}
+
+func AppendChatMessage(name string, text string, isSystem bool, isOwn bool) {
+	js.Global().Call("appendChatMessage", name, text, isSystem, isOwn)
+}
+
+func ClearChat() {
+	js.Global().Call("clearChat")
+}
+
+func RenderRoomMembers(players []interface{}, spectators []interface{}, localID string, ownerID string) {
+	js.Global().Call("renderRoomMembers", players, spectators, localID, ownerID)
+}
+
+func CreateTextImage(text string) js.Value {
+	return js.Global().Call("createTextImage", text)
+}
+
+func CreatePipImage(size int, filled bool) js.Value {
+	return js.Global().Call("createPipImage", size, filled)
+}
+
+func UpdateReadyButton(visible bool, ready bool) {
+	js.Global().Call("updateReadyButton", visible, ready)
+}
+
+func UpdateCountdown(seconds int) {
+	js.Global().Call("updateCountdown", seconds)
+}
+
+func LoadSessionToken() string {
+	return js.Global().Call("loadSessionToken").String()
+}
+
+func SaveSessionToken(token string) {
+	js.Global().Call("saveSessionToken", token)
+}
+
+func ShowError(message string) {
+	js.Global().Call("showError", message)
+}
+
 func SetLoadingProgress(progress float64, message string) {
 	js.Global().Call("setLoadingProgress", progress, message)
 }
@@ -39,3 +85,11 @@ func LoadFile(path string) js.Value {
 func LoadImage(path string) js.Value {
 	return js.Global().Call("loadImage", path)
 }
+
+func ShowReplayControls(visible bool) {
+	js.Global().Call("showReplayControls", visible)
+}
+
+func UpdateReplayControls(frame int, frameCount int, paused bool, speed float64) {
+	js.Global().Call("updateReplayControls", frame, frameCount, paused, speed)
+}
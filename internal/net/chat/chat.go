@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type Settings struct {
+	MaxLength  int
+	RateLimit  int
+	RateWindow time.Duration
+	Filters    []Filter
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		MaxLength:  200,
+		RateLimit:  5,
+		RateWindow: 5 * time.Second,
+	}
+}
+
+type Moderator struct {
+	settings Settings
+	sent     map[string][]time.Time
+	mu       sync.Mutex
+}
+
+func NewModerator(settings Settings) *Moderator {
+	return &Moderator{
+		settings: settings,
+		sent:     make(map[string][]time.Time),
+	}
+}
+
+func (m *Moderator) Moderate(playerID string, text string, filters ...Filter) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("message is empty")
+	}
+	if m.settings.MaxLength > 0 && utf8.RuneCountInString(text) > m.settings.MaxLength {
+		return "", fmt.Errorf("message is longer than %d characters", m.settings.MaxLength)
+	}
+
+	filters = append(filters[:len(filters):len(filters)], m.settings.Filters...)
+	for _, filter := range filters {
+		var err error
+		text, err = filter.Filter(playerID, text)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !m.allow(playerID) {
+		return "", fmt.Errorf("you are sending messages too fast")
+	}
+
+	return text, nil
+}
+
+func (m *Moderator) Forget(playerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sent, playerID)
+}
+
+func (m *Moderator) allow(playerID string) bool {
+	if m.settings.RateLimit <= 0 {
+		return true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	sent := m.sent[playerID]
+	for len(sent) > 0 && now.Sub(sent[0]) >= m.settings.RateWindow {
+		sent = sent[1:]
+	}
+
+	if len(sent) >= m.settings.RateLimit {
+		m.sent[playerID] = sent
+		return false
+	}
+
+	m.sent[playerID] = append(sent, now)
+	return true
+}
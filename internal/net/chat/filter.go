@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Filter interface {
+	Filter(playerID string, text string) (string, error)
+}
+
+type WordFilter struct {
+	pattern *regexp.Regexp
+}
+
+func NewWordFilter(words []string) *WordFilter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+
+	if len(quoted) == 0 {
+		return &WordFilter{}
+	}
+
+	sort.Slice(quoted, func(i, j int) bool {
+		return len(quoted[i]) > len(quoted[j])
+	})
+
+	return &WordFilter{
+		pattern: regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])(` + strings.Join(quoted, "|") + `)`),
+	}
+}
+
+func (f *WordFilter) Filter(playerID string, text string) (string, error) {
+	if f.pattern == nil {
+		return text, nil
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range f.pattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		if next, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(next) {
+			continue
+		}
+
+		b.WriteString(text[last:start])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[start:end])))
+		last = end
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+type MuteList struct {
+	muted map[string]time.Time
+	mu    sync.Mutex
+}
+
+func NewMuteList() *MuteList {
+	return &MuteList{
+		muted: make(map[string]time.Time),
+	}
+}
+
+func (l *MuteList) Mute(playerID string, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.muted[playerID] = time.Now().Add(duration)
+}
+
+func (l *MuteList) Unmute(playerID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.muted, playerID)
+}
+
+func (l *MuteList) IsMuted(playerID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until, exists := l.muted[playerID]
+	if !exists {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(l.muted, playerID)
+		return false
+	}
+
+	return true
+}
+
+func (l *MuteList) Muted() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	ids := make([]string, 0, len(l.muted))
+	for id, until := range l.muted {
+		if now.After(until) {
+			delete(l.muted, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (l *MuteList) Filter(playerID string, text string) (string, error) {
+	if l.IsMuted(playerID) {
+		return "", fmt.Errorf("you are muted")
+	}
+
+	return text, nil
+}
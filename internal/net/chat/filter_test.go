@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWordFilterMasksWholeWords(t *testing.T) {
+	filter := NewWordFilter([]string{"darn", " heck ", "", "дурак"})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"well darn it", "well **** it"},
+		{"HECK no", "**** no"},
+		{"darned hecklers", "darned hecklers"},
+		{"nothing to hide", "nothing to hide"},
+		{"darn darn", "**** ****"},
+		{"ты дурак", "ты *****"},
+		{"Дурак, правда", "*****, правда"},
+		{"дураки и придурак", "дураки и придурак"},
+		{"darné", "darné"},
+	}
+	for _, tt := range tests {
+		got, err := filter.Filter("p1", tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Filter(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyWordFilterKeepsText(t *testing.T) {
+	got, err := NewWordFilter(nil).Filter("p1", "anything goes")
+	if err != nil || got != "anything goes" {
+		t.Fatalf("Filter = %q, %v", got, err)
+	}
+}
+
+func TestMuteListBlocksUntilExpiry(t *testing.T) {
+	mutes := NewMuteList()
+	mutes.Mute("p1", 20*time.Millisecond)
+
+	if _, err := mutes.Filter("p1", "hi"); err == nil {
+		t.Fatal("muted player passed the filter")
+	}
+	if _, err := mutes.Filter("p2", "hi"); err != nil {
+		t.Fatalf("other player was blocked: %v", err)
+	}
+	if muted := mutes.Muted(); len(muted) != 1 || muted[0] != "p1" {
+		t.Fatalf("Muted() = %v", muted)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if mutes.IsMuted("p1") || len(mutes.Muted()) != 0 {
+		t.Fatal("mute did not expire")
+	}
+}
+
+func TestMuteListUnmute(t *testing.T) {
+	mutes := NewMuteList()
+	mutes.Mute("p1", time.Hour)
+	mutes.Unmute("p1")
+
+	if text, err := mutes.Filter("p1", "hi"); err != nil || text != "hi" {
+		t.Fatalf("Filter = %q, %v", text, err)
+	}
+}
+
+func TestModeratorAppliesFilters(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Filters = []Filter{NewWordFilter([]string{"darn"})}
+	moderator := NewModerator(settings)
+
+	mutes := NewMuteList()
+	mutes.Mute("p2", time.Hour)
+
+	if text, err := moderator.Moderate("p1", "  darn  ", mutes); err != nil || text != "****" {
+		t.Fatalf("Moderate = %q, %v", text, err)
+	}
+	if _, err := moderator.Moderate("p2", "hello", mutes); err == nil {
+		t.Fatal("muted player passed the moderator")
+	}
+}
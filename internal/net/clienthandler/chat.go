@@ -0,0 +1,52 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"syscall/js"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/utils"
+)
+
+func sendChat(this js.Value, args []js.Value) interface{} {
+	text := args[0].String()
+	if text == "" {
+		return nil
+	}
+
+	msg := message.Message{
+		Type: message.ChatMessageMsg,
+		Data: text,
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func handleChatMessage(data interface{}) {
+	var chatMessage message.ChatMessage
+	if err := utils.ParseInterfaceToJSON(data, &chatMessage); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	appendChatMessage(chatMessage)
+}
+
+func handleChatHistory(data interface{}) {
+	var history []message.ChatMessage
+	if err := utils.ParseInterfaceToJSON(data, &history); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	jsfunc.ClearChat()
+	for _, chatMessage := range history {
+		appendChatMessage(chatMessage)
+	}
+}
+
+func appendChatMessage(chatMessage message.ChatMessage) {
+	jsfunc.AppendChatMessage(chatMessage.Name, chatMessage.Text, chatMessage.PlayerID == "", chatMessage.PlayerID != "" && chatMessage.PlayerID == playerInfo.ID)
+}
@@ -3,20 +3,41 @@
 package clienthandler
 
 import (
+	"strconv"
 	"syscall/js"
+	"time"
 	"webgl-app/internal/config"
 	"webgl-app/internal/game/game"
 	"webgl-app/internal/graphics/webgl"
 	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/codec"
 	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/protocol"
 	"webgl-app/internal/net/room"
 )
 
+const (
+	minReconnectDelay  = 500 * time.Millisecond
+	maxReconnectDelay  = 10 * time.Second
+	maxPendingMessages = 128
+
+	maxReconnectAttempts = 10
+	closePolicyViolation = 1008
+)
+
 var (
-	socket     js.Value
-	roomInfo   message.RoomInfo
-	playerInfo message.PlayerInfo
-	gm         *game.Game
+	socket          js.Value
+	wireCodec       codec.Codec
+	sessionToken    string
+	reconnectDelay  = minReconnectDelay
+	reconnects      int
+	noReconnect     bool
+	welcomed        bool
+	pendingMessages []message.Message
+	spectating      bool
+	roomInfo        message.RoomInfo
+	playerInfo      message.PlayerInfo
+	gm              *game.Game
 )
 
 func RegisterCallbacks() {
@@ -24,22 +45,38 @@ func RegisterCallbacks() {
 
 	js.Global().Set("createLobby", js.FuncOf(createLobby))
 	js.Global().Set("joinLobby", js.FuncOf(joinLobby))
+	js.Global().Set("spectateLobby", js.FuncOf(spectateLobby))
+	js.Global().Set("quickMatch", js.FuncOf(quickMatch))
+	js.Global().Set("cancelQuickMatch", js.FuncOf(cancelQuickMatch))
+	js.Global().Set("browseLobbies", js.FuncOf(browseLobbies))
+	js.Global().Set("refreshRoomList", js.FuncOf(refreshRoomList))
+	js.Global().Set("changeRoomsPage", js.FuncOf(changeRoomsPage))
+	js.Global().Set("closeRoomBrowser", js.FuncOf(closeRoomBrowser))
+	js.Global().Set("joinListedRoom", js.FuncOf(joinListedRoom))
 	js.Global().Set("leaveLobby", js.FuncOf(leaveLobby))
 	js.Global().Set("startGame", js.FuncOf(startGame))
+	js.Global().Set("sendChat", js.FuncOf(sendChat))
+	js.Global().Set("setProfile", js.FuncOf(setProfile))
+	js.Global().Set("kickPlayer", js.FuncOf(kickPlayer))
+	js.Global().Set("mutePlayer", js.FuncOf(mutePlayer))
+	js.Global().Set("lockRoom", js.FuncOf(lockRoom))
+	js.Global().Set("setRoomPassword", js.FuncOf(setRoomPassword))
+	js.Global().Set("transferOwner", js.FuncOf(transferOwner))
+	js.Global().Set("toggleReady", js.FuncOf(toggleReady))
+	registerReplayCallbacks()
 
 	jsfunc.LogInfo(" ----- Connecting to WebSocket ----- ")
 
-	jsfunc.SetLoadingProgress(100, "Initialization...")
+	jsfunc.SetLoadingProgress(100, "Connecting...")
+	sessionToken = jsfunc.LoadSessionToken()
 	connectWebSocket()
 
-	jsfunc.ShowScreen("main_menu")
-
 	<-c
 }
 
 func InitGame(GLCtx *webgl.GLContext) error {
 	var err error
-	gm, err = game.NewGame(&socket, GLCtx)
+	gm, err = game.NewGame(sendMessage, GLCtx)
 	if err != nil {
 		return err
 	}
@@ -51,32 +88,89 @@ func connectWebSocket() {
 		socket.Call("close")
 	}
 
+	wireCodec = codec.JSON{}
+
 	wsURL := js.Global().Call("getWebSocketURL").String()
 	socket = js.Global().Get("WebSocket").New(wsURL)
+	socket.Set("binaryType", "arraybuffer")
 
 	socket.Set("onopen", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		jsfunc.LogInfo("WebSocket connected")
+		welcomed = false
+		hello := protocol.NewHello(config.ProgramConfig.Codec)
+		hello.SessionToken = sessionToken
+		sendMessage(message.Message{
+			Type: message.HelloMsg,
+			Data: hello,
+		})
 		return nil
 	}))
 
 	socket.Set("onmessage", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		handleServerMessage(args[0].Get("data").String())
+		data := args[0].Get("data")
+		handleServerMessage(readSocketData(data), decoderFor(data))
 		return nil
 	}))
 
 	socket.Set("onerror", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		jsfunc.LogError("WebSocket connection error")
-		socket.Call("close")
-		gm.Stop()
 		return nil
 	}))
 
 	socket.Set("onclose", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if !this.Equal(socket) {
+			return nil
+		}
+
 		jsfunc.LogInfo("WebSocket connection closed")
+		if noReconnect {
+			return nil
+		}
+		if args[0].Get("code").Int() == closePolicyViolation {
+			stopReconnecting("Disconnected by the server, please reload the page")
+			return nil
+		}
+
+		scheduleReconnect()
 		return nil
 	}))
 }
 
+func scheduleReconnect() {
+	if reconnects >= maxReconnectAttempts {
+		stopReconnecting("Connection lost, please reload the page")
+		return
+	}
+	reconnects++
+
+	jsfunc.ShowError("Connection lost, reconnecting...")
+
+	delay := reconnectDelay
+	reconnectDelay *= 2
+	if reconnectDelay > maxReconnectDelay {
+		reconnectDelay = maxReconnectDelay
+	}
+
+	var reconnect js.Func
+	reconnect = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		reconnect.Release()
+		connectWebSocket()
+		return nil
+	})
+	js.Global().Call("setTimeout", reconnect, delay.Milliseconds())
+}
+
+func stopReconnecting(reason string) {
+	noReconnect = true
+	pendingMessages = nil
+	gm.Stop()
+
+	if reason != "" {
+		jsfunc.LogError(reason)
+		jsfunc.ShowError(reason)
+	}
+}
+
 func createLobby(this js.Value, args []js.Value) interface{} {
 	var needPlayers int
 	if config.ProgramConfig.Debug {
@@ -85,11 +179,27 @@ func createLobby(this js.Value, args []js.Value) interface{} {
 		needPlayers = 2
 	}
 
+	public := js.Global().Get("document").Call("getElementById", "public_lobby").Get("checked").Bool()
+
+	ownerLeave := room.CloseRoom
+	if js.Global().Get("document").Call("getElementById", "keep_lobby").Get("checked").Bool() {
+		ownerLeave = room.MigrateOwner
+	}
+
+	bestOf, err := strconv.Atoi(js.Global().Get("document").Call("getElementById", "best_of").Get("value").String())
+	if err != nil {
+		bestOf = room.DefaultBestOf
+	}
+
 	msg := message.Message{
 		Type: message.CreateRoomMsg,
 		Data: room.RoomSettings{
+			Public:      public,
+			Record:      true,
 			MaxPlayers:  2,
 			NeedPlayers: needPlayers,
+			OwnerLeave:  ownerLeave,
+			BestOf:      bestOf,
 		},
 	}
 
@@ -98,11 +208,39 @@ func createLobby(this js.Value, args []js.Value) interface{} {
 }
 
 func joinLobby(this js.Value, args []js.Value) interface{} {
-	roomCode := js.Global().Get("document").Call("getElementById", "room_code").Get("value").String()
-
 	msg := message.Message{
 		Type: message.JoinRoomMsg,
-		Data: roomCode,
+		Data: joinRoomRequest(),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func spectateLobby(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.SpectateRoomMsg,
+		Data: joinRoomRequest(),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func quickMatch(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.QueueJoinMsg,
+		Data: nil,
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func cancelQuickMatch(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.QueueLeaveMsg,
+		Data: nil,
 	}
 
 	sendMessage(msg)
@@ -119,6 +257,16 @@ func leaveLobby(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+func toggleReady(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.SetReadyMsg,
+		Data: !isReady(playerInfo.ID),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
 func startGame(this js.Value, args []js.Value) interface{} {
 	msg := message.Message{
 		Type: message.StartGameMsg,
@@ -3,25 +3,32 @@
 package clienthandler
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/codec"
 	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/protocol"
 	"webgl-app/internal/utils"
 )
 
-func handleServerMessage(raw string) {
-	var msg message.Message
-	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+func handleServerMessage(raw []byte, decoder codec.Codec) {
+	msg, err := decoder.Decode(raw)
+	if err != nil {
 		jsfunc.LogError(fmt.Sprint("Failed to parse message: ", err.Error()))
 		return
 	}
 
 	switch msg.Type {
+	case message.WelcomeMsg:
+		handleWelcome(msg.Data)
 	case message.CreateRoomMsg:
 		handleCreateRoom(msg.Data)
 	case message.JoinRoomMsg:
 		handleJoinRoom(msg.Data)
+	case message.SpectateRoomMsg:
+		handleSpectateRoom(msg.Data)
 	case message.LeaveRoomMsg:
 		handleLeaveRoom(msg.Data)
 	case message.StartGameMsg:
@@ -40,6 +47,26 @@ func handleServerMessage(raw string) {
 		handleRoomClosed(msg.Data)
 	case message.GameStateMsg:
 		handleGameState(msg.Data)
+	case message.ConfirmedInputsMsg:
+		handleConfirmedInputs(msg.Data)
+	case message.ResumeGameMsg:
+		handleResumeGame(msg.Data)
+	case message.ListRoomsMsg:
+		handleListRooms(msg.Data)
+	case message.RoomListUpdateMsg:
+		handleRoomListUpdate(msg.Data)
+	case message.QueueStatusMsg:
+		handleQueueStatus(msg.Data)
+	case message.ChatMessageMsg:
+		handleChatMessage(msg.Data)
+	case message.ChatHistoryMsg:
+		handleChatHistory(msg.Data)
+	case message.RoomEventMsg:
+		handleRoomEvent(msg.Data)
+	case message.TimeSyncMsg:
+		handleTimeSync(msg.Data)
+	case message.RoundEndMsg:
+		handleRoundEnd(msg.Data)
 	case message.ErrorMsg:
 		handleError(msg.Data)
 	default:
@@ -47,19 +74,72 @@ func handleServerMessage(raw string) {
 	}
 }
 
+func handleWelcome(data interface{}) {
+	var welcome message.Welcome
+	if err := utils.ParseInterfaceToJSON(data, &welcome); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	c, err := codec.ByName(welcome.Codec)
+	if err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+	wireCodec = c
+
+	if welcome.BuildHash != protocol.BuildHash {
+		jsfunc.LogWarn(fmt.Sprintf("Server build %s differs from client build %s", welcome.BuildHash, protocol.BuildHash))
+	}
+	jsfunc.LogInfo(fmt.Sprintf("Protocol v%d negotiated (codec: %s, compression: %s)", welcome.ProtocolVersion, welcome.Codec, welcome.Compression))
+
+	sessionToken = welcome.SessionToken
+	jsfunc.SaveSessionToken(sessionToken)
+	welcomed = true
+	reconnects = 0
+	reconnectDelay = minReconnectDelay
+	resetClockSync()
+
+	if welcome.Resumed {
+		jsfunc.LogInfo("Session resumed")
+		flushPendingMessages()
+		return
+	}
+
+	pendingMessages = nil
+	gm.Stop()
+	sendUpdatePlayerInfoMsg()
+	jsfunc.ShowScreen(jsfunc.MainMenuScreen)
+}
+
 func handleCreateRoom(data interface{}) {
+	spectating = false
+	sendTimeSyncMsg()
 	sendUpdateRoomInfoMsg()
 	sendUpdatePlayerInfoMsg()
 	jsfunc.ShowScreen(jsfunc.LobbyScreen)
 }
 
 func handleJoinRoom(data interface{}) {
+	spectating = false
+	sendTimeSyncMsg()
+	sendUpdateRoomInfoMsg()
+	sendUpdatePlayerInfoMsg()
+	jsfunc.ShowScreen(jsfunc.LobbyScreen)
+}
+
+func handleSpectateRoom(data interface{}) {
+	spectating = true
+	sendTimeSyncMsg()
 	sendUpdateRoomInfoMsg()
 	sendUpdatePlayerInfoMsg()
 	jsfunc.ShowScreen(jsfunc.LobbyScreen)
 }
 
 func handleLeaveRoom(data interface{}) {
+	spectating = false
+	gm.Stop()
+	jsfunc.ClearChat()
 	jsfunc.ShowScreen(jsfunc.MainMenuScreen)
 }
 
@@ -71,7 +151,7 @@ func handleStartGame(data interface{}) {
 	utils.ParseInterfaceToJSON(data, &gameData)
 
 	gm.Stop()
-	gm.Start(playerInfo.ID, gameData.FightersPositions)
+	gm.Start(localPlayerID(), gameData, serverToLocalTime(gameData.StartAt, gameData.ServerTime))
 }
 
 func handleEndGame(data interface{}) {
@@ -108,15 +188,110 @@ func handlePlayerJoin(data interface{}) {
 
 func handleRoomClosed(data interface{}) {
 	gm.Stop()
+	jsfunc.ClearChat()
 	jsfunc.ShowScreen(jsfunc.MainMenuScreen)
 }
 
 func handleGameState(data interface{}) {
-	var playerState message.FighterInfo
-	utils.ParseInterfaceToJSON(data, &playerState)
-	gm.UpdatePlayersData(playerState)
+	var snapshot message.GameSnapshot
+	if err := utils.ParseInterfaceToJSON(data, &snapshot); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+	gm.ApplySnapshot(snapshot)
 }
 
-func handleError(data interface{}) {
+func handleRoundEnd(data interface{}) {
+	var result message.RoundResult
+	if err := utils.ParseInterfaceToJSON(data, &result); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	score := make([]string, 0, len(roomInfo.Players))
+	for _, member := range roomInfo.Players {
+		score = append(score, fmt.Sprintf("%s %d", member.Name, result.Wins[member.ID]))
+	}
+
+	var text string
+	switch {
+	case result.MatchWinnerID != "":
+		text = fmt.Sprintf("%s wins the match (%s)", memberName(result.MatchWinnerID), strings.Join(score, ", "))
+	case result.WinnerID != "":
+		text = fmt.Sprintf("Round %d goes to %s (%s)", result.Round, memberName(result.WinnerID), strings.Join(score, ", "))
+	default:
+		text = fmt.Sprintf("Round %d is a draw (%s)", result.Round, strings.Join(score, ", "))
+	}
+
+	jsfunc.AppendChatMessage("", text, true, false)
+}
+
+func handleConfirmedInputs(data interface{}) {
+	var inputs message.ConfirmedInputs
+	if err := utils.ParseInterfaceToJSON(data, &inputs); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+	gm.ConfirmInputs(inputs)
+}
+
+func handleResumeGame(data interface{}) {
+	var resumeData message.ResumeGameData
+	if err := utils.ParseInterfaceToJSON(data, &resumeData); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	sendUpdateRoomInfoMsg()
+	jsfunc.ShowScreen(jsfunc.GameScreenScreen)
+
 	gm.Stop()
+	gm.Start(localPlayerID(), resumeData.StartGame, time.Time{})
+	gm.Resume(resumeData.Inputs)
+}
+
+func handleQueueStatus(data interface{}) {
+	var status message.QueueStatus
+	if err := utils.ParseInterfaceToJSON(data, &status); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	if status.Matched {
+		jsfunc.LogInfo("Opponent found")
+		return
+	}
+	if !status.InQueue {
+		jsfunc.ShowScreen(jsfunc.MainMenuScreen)
+		return
+	}
+
+	updateQueueUi(status)
+	jsfunc.ShowScreen(jsfunc.MatchmakingScreen)
+}
+
+func handleError(data interface{}) {
+	if !welcomed {
+		stopReconnecting("")
+	}
+
+	if text, ok := data.(string); ok {
+		jsfunc.LogError(text)
+		jsfunc.ShowError(text)
+		return
+	}
+
+	var info message.Error
+	if err := utils.ParseInterfaceToJSON(data, &info); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	if info.Code == message.NoMatchError {
+		gm.Stop()
+		sendUpdateRoomInfoMsg()
+		jsfunc.ShowScreen(jsfunc.LobbyScreen)
+	}
+	jsfunc.LogError(fmt.Sprintf("%s: %s", info.Code, info.Message))
+	jsfunc.ShowError(info.Message)
 }
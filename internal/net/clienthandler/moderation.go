@@ -0,0 +1,114 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"fmt"
+	"syscall/js"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/utils"
+)
+
+func joinRoomRequest() message.JoinRoomRequest {
+	document := js.Global().Get("document")
+
+	return message.JoinRoomRequest{
+		RoomID:   document.Call("getElementById", "room_code").Get("value").String(),
+		Password: document.Call("getElementById", "room_password").Get("value").String(),
+	}
+}
+
+func kickPlayer(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.KickPlayerMsg,
+		Data: message.KickRequest{
+			PlayerID:   args[0].String(),
+			BanSeconds: args[1].Int(),
+		},
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func mutePlayer(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.MutePlayerMsg,
+		Data: message.MuteRequest{
+			PlayerID: args[0].String(),
+			Seconds:  args[1].Int(),
+		},
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func lockRoom(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.LockRoomMsg,
+		Data: args[0].Bool(),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func setRoomPassword(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.SetRoomPasswordMsg,
+		Data: args[0].String(),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func transferOwner(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.TransferOwnerMsg,
+		Data: args[0].String(),
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func handleRoomEvent(data interface{}) {
+	var event message.RoomEvent
+	if err := utils.ParseInterfaceToJSON(data, &event); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	var text string
+	switch event.Kind {
+	case message.PlayerKickedEvent:
+		text = fmt.Sprintf("%s was kicked from the room", event.PlayerName)
+	case message.PlayerBannedEvent:
+		text = fmt.Sprintf("%s was banned from the room", event.PlayerName)
+	case message.PlayerMutedEvent:
+		text = fmt.Sprintf("%s was muted", event.PlayerName)
+	case message.PlayerUnmutedEvent:
+		text = fmt.Sprintf("%s was unmuted", event.PlayerName)
+	case message.RoomLockedEvent:
+		text = "The room is now locked"
+	case message.RoomUnlockedEvent:
+		text = "The room is now unlocked"
+	case message.PasswordSetEvent:
+		text = "The room password was changed"
+	case message.PasswordClearedEvent:
+		text = "The room password was removed"
+	case message.OwnerChangedEvent:
+		text = fmt.Sprintf("%s is now the room owner", event.PlayerName)
+	default:
+		return
+	}
+
+	if event.PlayerID == playerInfo.ID && (event.Kind == message.PlayerKickedEvent || event.Kind == message.PlayerBannedEvent) {
+		jsfunc.ShowError(text)
+	}
+
+	jsfunc.AppendChatMessage("", text, true, false)
+}
@@ -0,0 +1,20 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"syscall/js"
+	"webgl-app/internal/net/message"
+)
+
+func setProfile(this js.Value, args []js.Value) interface{} {
+	msg := message.Message{
+		Type: message.SetProfileMsg,
+		Data: message.Profile{
+			Name: args[0].String(),
+		},
+	}
+
+	sendMessage(msg)
+	return nil
+}
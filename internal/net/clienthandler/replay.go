@@ -0,0 +1,71 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"bytes"
+	"syscall/js"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/replay"
+)
+
+func registerReplayCallbacks() {
+	js.Global().Set("loadReplay", js.FuncOf(loadReplay))
+	js.Global().Set("replayTogglePause", js.FuncOf(replayTogglePause))
+	js.Global().Set("replayStep", js.FuncOf(replayStep))
+	js.Global().Set("replaySetSpeed", js.FuncOf(replaySetSpeed))
+	js.Global().Set("replaySeek", js.FuncOf(replaySeek))
+	js.Global().Set("replayShowColliders", js.FuncOf(replayShowColliders))
+	js.Global().Set("replayExit", js.FuncOf(replayExit))
+}
+
+func loadReplay(this js.Value, args []js.Value) interface{} {
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	r, err := replay.Decode(bytes.NewReader(data))
+	if err != nil {
+		jsfunc.LogError(err.Error())
+		jsfunc.ShowError("Failed to load replay: " + err.Error())
+		return nil
+	}
+
+	jsfunc.ShowScreen(jsfunc.GameScreenScreen)
+	if err := gm.StartReplay(r); err != nil {
+		jsfunc.LogError(err.Error())
+		jsfunc.ShowError("Failed to play replay: " + err.Error())
+		jsfunc.ShowScreen(jsfunc.MainMenuScreen)
+	}
+
+	return nil
+}
+
+func replayTogglePause(this js.Value, args []js.Value) interface{} {
+	gm.TogglePause()
+	return nil
+}
+
+func replayStep(this js.Value, args []js.Value) interface{} {
+	gm.StepReplay()
+	return nil
+}
+
+func replaySetSpeed(this js.Value, args []js.Value) interface{} {
+	gm.SetReplaySpeed(args[0].Float())
+	return nil
+}
+
+func replaySeek(this js.Value, args []js.Value) interface{} {
+	gm.SeekReplay(args[0].Int())
+	return nil
+}
+
+func replayShowColliders(this js.Value, args []js.Value) interface{} {
+	gm.ToggleColliders(args[0].Bool())
+	return nil
+}
+
+func replayExit(this js.Value, args []js.Value) interface{} {
+	gm.StopReplay()
+	return nil
+}
@@ -0,0 +1,161 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"syscall/js"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/utils"
+)
+
+var (
+	roomList  message.RoomList
+	roomsPage int
+)
+
+func browseLobbies(this js.Value, args []js.Value) interface{} {
+	roomsPage = 0
+	sendListRoomsMsg()
+	jsfunc.ShowScreen(jsfunc.RoomBrowserScreen)
+	return nil
+}
+
+func refreshRoomList(this js.Value, args []js.Value) interface{} {
+	roomsPage = 0
+	sendListRoomsMsg()
+	return nil
+}
+
+func changeRoomsPage(this js.Value, args []js.Value) interface{} {
+	page := roomsPage + args[0].Int()
+	if page < 0 || page >= pageCount() {
+		return nil
+	}
+
+	roomsPage = page
+	sendListRoomsMsg()
+	return nil
+}
+
+func closeRoomBrowser(this js.Value, args []js.Value) interface{} {
+	sendUnsubscribeRoomsMsg()
+	jsfunc.ShowScreen(jsfunc.MainMenuScreen)
+	return nil
+}
+
+func joinListedRoom(this js.Value, args []js.Value) interface{} {
+	sendUnsubscribeRoomsMsg()
+
+	request := message.JoinRoomRequest{
+		RoomID: args[0].String(),
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		request.Password = args[1].String()
+	}
+
+	msg := message.Message{
+		Type: message.JoinRoomMsg,
+		Data: request,
+	}
+
+	sendMessage(msg)
+	return nil
+}
+
+func handleListRooms(data interface{}) {
+	if err := utils.ParseInterfaceToJSON(data, &roomList); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	roomsPage = roomList.Page
+	renderRoomList()
+}
+
+func handleRoomListUpdate(data interface{}) {
+	var update message.RoomListUpdate
+	if err := utils.ParseInterfaceToJSON(data, &update); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	for i, info := range roomList.Rooms {
+		if info.ID != update.Room.ID {
+			continue
+		}
+
+		if update.Removed {
+			roomList.Rooms = append(roomList.Rooms[:i], roomList.Rooms[i+1:]...)
+			roomList.Total--
+		} else {
+			roomList.Rooms[i] = update.Room
+		}
+		renderRoomList()
+		return
+	}
+
+	if !update.Removed {
+		roomList.Total++
+		if len(roomList.Rooms) < roomList.PageSize {
+			roomList.Rooms = append(roomList.Rooms, update.Room)
+		}
+		renderRoomList()
+	}
+}
+
+func renderRoomList() {
+	rooms := make([]interface{}, 0, len(roomList.Rooms))
+	for _, info := range roomList.Rooms {
+		rooms = append(rooms, map[string]interface{}{
+			"id":         info.ID,
+			"status":     info.Status,
+			"players":    info.PlayersCount,
+			"maxPlayers": info.MaxPlayers,
+			"spectators": info.SpectatorsCount,
+			"locked":     info.Locked,
+			"password":   info.HasPassword,
+			"bestOf":     info.BestOf,
+		})
+	}
+
+	jsfunc.RenderRoomList(rooms, roomsPage, pageCount())
+}
+
+func pageCount() int {
+	if roomList.PageSize <= 0 {
+		return 1
+	}
+	return (roomList.Total + roomList.PageSize - 1) / roomList.PageSize
+}
+
+func sendListRoomsMsg() {
+	document := js.Global().Get("document")
+
+	filter := message.RoomFilter{
+		Status: document.Call("getElementById", "room_filter_status").Get("value").String(),
+	}
+	if document.Call("getElementById", "room_filter_free").Get("checked").Bool() {
+		filter.MinFreeSlots = 1
+	}
+
+	msg := message.Message{
+		Type: message.ListRoomsMsg,
+		Data: message.ListRoomsRequest{
+			Filter:    filter,
+			Page:      roomsPage,
+			Subscribe: true,
+		},
+	}
+
+	sendMessage(msg)
+}
+
+func sendUnsubscribeRoomsMsg() {
+	msg := message.Message{
+		Type: message.UnsubscribeRoomsMsg,
+		Data: nil,
+	}
+
+	sendMessage(msg)
+}
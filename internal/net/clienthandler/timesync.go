@@ -0,0 +1,63 @@
+//go:build js
+
+package clienthandler
+
+import (
+	"syscall/js"
+	"time"
+	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/utils"
+)
+
+var (
+	clockOffset    int64
+	clockRoundTrip int64 = -1
+)
+
+func resetClockSync() {
+	clockOffset = 0
+	clockRoundTrip = -1
+	sendTimeSyncMsg()
+}
+
+func sendTimeSyncMsg() {
+	msg := message.Message{
+		Type: message.TimeSyncMsg,
+		Data: message.TimeSync{
+			ClientTime: localTimeMillis(),
+		},
+	}
+
+	sendMessage(msg)
+}
+
+func handleTimeSync(data interface{}) {
+	var sync message.TimeSync
+	if err := utils.ParseInterfaceToJSON(data, &sync); err != nil {
+		jsfunc.LogError(err.Error())
+		return
+	}
+
+	now := localTimeMillis()
+	roundTrip := now - sync.ClientTime
+	if roundTrip < 0 || (clockRoundTrip >= 0 && roundTrip >= clockRoundTrip) {
+		return
+	}
+
+	clockRoundTrip = roundTrip
+	clockOffset = sync.ServerTime + roundTrip/2 - now
+}
+
+func serverToLocalTime(serverTime int64, sentAt int64) time.Time {
+	offset := clockOffset
+	if clockRoundTrip < 0 {
+		offset = sentAt - localTimeMillis()
+	}
+
+	return time.UnixMilli(serverTime - offset)
+}
+
+func localTimeMillis() int64 {
+	return int64(js.Global().Get("Date").Call("now").Float())
+}
@@ -3,10 +3,10 @@
 package clienthandler
 
 import (
-	"encoding/json"
 	"fmt"
 	"syscall/js"
 	"webgl-app/internal/jsfunc"
+	"webgl-app/internal/net/codec"
 	"webgl-app/internal/net/message"
 	"webgl-app/internal/net/room"
 )
@@ -30,19 +30,73 @@ func sendUpdatePlayerInfoMsg() {
 }
 
 func sendMessage(msg message.Message) {
-	jsonData, err := json.Marshal(msg)
+	if !socketOpen() {
+		if len(pendingMessages) < maxPendingMessages {
+			pendingMessages = append(pendingMessages, msg)
+		}
+		return
+	}
+
+	writeMessage(msg)
+}
+
+func flushPendingMessages() {
+	messages := pendingMessages
+	pendingMessages = nil
+
+	for _, msg := range messages {
+		sendMessage(msg)
+	}
+}
+
+func socketOpen() bool {
+	if socket.IsUndefined() {
+		return false
+	}
+	return socket.Get("readyState").Int() == js.Global().Get("WebSocket").Get("OPEN").Int()
+}
+
+func writeMessage(msg message.Message) {
+	data, err := wireCodec.Encode(msg)
 	if err != nil {
-		jsfunc.LogError(fmt.Sprint("JSON error:", err.Error()))
+		jsfunc.LogError(fmt.Sprint("Encode error:", err.Error()))
 		return
 	}
-	socket.Call("send", string(jsonData))
+
+	if wireCodec.IsBinary() {
+		buf := js.Global().Get("Uint8Array").New(len(data))
+		js.CopyBytesToJS(buf, data)
+		socket.Call("send", buf)
+	} else {
+		socket.Call("send", string(data))
+	}
+}
+
+func readSocketData(data js.Value) []byte {
+	if data.Type() == js.TypeString {
+		return []byte(data.String())
+	}
+
+	buf := js.Global().Get("Uint8Array").New(data)
+	raw := make([]byte, buf.Get("length").Int())
+	js.CopyBytesToGo(raw, buf)
+
+	return raw
 }
 
 func updateUi() {
+	if playerInfo.ID != "" {
+		js.Global().Get("document").Call("getElementById", "player_name").Set("textContent", playerInfo.Name)
+		js.Global().Get("document").Call("getElementById", "player_rating").Set("textContent", fmt.Sprintf("Rating: %.0f ± %.0f", playerInfo.Rating, playerInfo.RatingDeviation))
+	}
 	js.Global().Get("document").Call("getElementById", "lobby_code").Set("textContent", roomInfo.ID)
 	js.Global().Get("document").Call("getElementById", "room_status").Set("textContent", fmt.Sprintf("Status: %s", roomInfo.Status))
 	js.Global().Get("document").Call("getElementById", "current_players").Set("textContent", roomInfo.PlayersCount)
 	js.Global().Get("document").Call("getElementById", "max_players").Set("textContent", roomInfo.MaxPlayers)
+	js.Global().Get("document").Call("getElementById", "current_spectators").Set("textContent", roomInfo.SpectatorsCount)
+	jsfunc.RenderRoomMembers(roomMembers(roomInfo.Players), roomMembers(roomInfo.Spectators), playerInfo.ID, roomInfo.OwnerId)
+	jsfunc.UpdateReadyButton(!spectating, isReady(playerInfo.ID))
+	js.Global().Get("document").Call("getElementById", "room_locked").Set("checked", roomInfo.Locked)
 	if playerInfo.ID == roomInfo.OwnerId {
 		jsfunc.UpdateOwnerControls(true)
 		if roomInfo.Status == string(room.Ready) {
@@ -54,3 +108,74 @@ func updateUi() {
 		jsfunc.UpdateOwnerControls(false)
 	}
 }
+
+func roomMembers(members []message.PlayerInfo) []interface{} {
+	result := make([]interface{}, len(members))
+	for i, member := range members {
+		result[i] = map[string]interface{}{
+			"id":    member.ID,
+			"name":  member.Name,
+			"ready": isReady(member.ID),
+			"muted": isMuted(member.ID),
+		}
+	}
+
+	return result
+}
+
+func memberName(id string) string {
+	for _, member := range roomInfo.Players {
+		if member.ID == id {
+			return member.Name
+		}
+	}
+	return id
+}
+
+func isReady(id string) bool {
+	for _, readyID := range roomInfo.ReadyPlayers {
+		if readyID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isMuted(id string) bool {
+	for _, mutedID := range roomInfo.MutedPlayers {
+		if mutedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func updateQueueUi(status message.QueueStatus) {
+	document := js.Global().Get("document")
+
+	document.Call("getElementById", "queue_status").Set("textContent", fmt.Sprintf("Searching for an opponent... (%d in queue)", status.QueueSize))
+
+	wait := fmt.Sprintf("Waiting %s", formatSeconds(status.WaitedSeconds))
+	if status.EstimatedWaitSeconds > 0 {
+		wait += fmt.Sprintf(", about %s left", formatSeconds(status.EstimatedWaitSeconds))
+	}
+	document.Call("getElementById", "queue_wait").Set("textContent", wait)
+}
+
+func formatSeconds(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func localPlayerID() string {
+	if spectating {
+		return ""
+	}
+	return playerInfo.ID
+}
+
+func decoderFor(data js.Value) codec.Codec {
+	if data.Type() == js.TypeString {
+		return codec.JSON{}
+	}
+	return codec.Binary{}
+}
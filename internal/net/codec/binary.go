@@ -0,0 +1,287 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"webgl-app/internal/graphics/primitives"
+	"webgl-app/internal/net/message"
+)
+
+const (
+	tagEnvelope byte = iota
+	tagPlayerInput
+	tagConfirmedInputs
+	tagGameState
+)
+
+const (
+	controlMoveLeft byte = 1 << iota
+	controlMoveRight
+	controlJump
+	controlAttack
+)
+
+const (
+	snapshotStart byte = 1 << iota
+	snapshotEnd
+)
+
+// Three string lengths, animation frame, health, specular flag and two rects.
+const minFighterSnapshotSize = 3 + 1 + 4 + 1 + 2*16
+
+type Binary struct{}
+
+func (Binary) Name() string {
+	return BinaryName
+}
+
+func (Binary) IsBinary() bool {
+	return true
+}
+
+func (Binary) Encode(msg message.Message) ([]byte, error) {
+	switch data := msg.Data.(type) {
+	case message.FrameInput:
+		if msg.Type == message.PlayerInputMsg {
+			buf := []byte{tagPlayerInput}
+			buf = binary.AppendUvarint(buf, data.Frame)
+			return append(buf, EncodeControl(data.Control)), nil
+		}
+	case message.ConfirmedInputs:
+		if msg.Type == message.ConfirmedInputsMsg {
+			buf := []byte{tagConfirmedInputs}
+			buf = binary.AppendUvarint(buf, data.Frame)
+			buf = binary.AppendUvarint(buf, uint64(len(data.Controls)))
+			for _, control := range data.Controls {
+				buf = append(buf, EncodeControl(control))
+			}
+			return buf, nil
+		}
+	case message.GameSnapshot:
+		if msg.Type == message.GameStateMsg {
+			return encodeSnapshot([]byte{tagGameState}, data), nil
+		}
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{tagEnvelope}, payload...), nil
+}
+
+func (Binary) Decode(data []byte) (message.Message, error) {
+	if len(data) == 0 {
+		return message.Message{}, fmt.Errorf("empty message")
+	}
+
+	r := &reader{buf: data[1:]}
+
+	switch data[0] {
+	case tagEnvelope:
+		var msg message.Message
+		err := json.Unmarshal(data[1:], &msg)
+		return msg, err
+
+	case tagPlayerInput:
+		input := message.FrameInput{
+			Frame:   r.uvarint(),
+			Control: DecodeControl(r.byte()),
+		}
+		return message.Message{Type: message.PlayerInputMsg, Data: input}, r.err
+
+	case tagConfirmedInputs:
+		inputs := message.ConfirmedInputs{
+			Frame: r.uvarint(),
+		}
+		count := r.count(1)
+		inputs.Controls = make([]message.FighterControl, 0, count)
+		for i := 0; i < count; i++ {
+			inputs.Controls = append(inputs.Controls, DecodeControl(r.byte()))
+		}
+		return message.Message{Type: message.ConfirmedInputsMsg, Data: inputs}, r.err
+
+	case tagGameState:
+		snapshot := decodeSnapshot(r)
+		return message.Message{Type: message.GameStateMsg, Data: snapshot}, r.err
+
+	default:
+		return message.Message{}, fmt.Errorf("unknown binary message tag: %d", data[0])
+	}
+}
+
+func EncodeControl(control message.FighterControl) byte {
+	var b byte
+	if control.MoveLeft {
+		b |= controlMoveLeft
+	}
+	if control.MoveRight {
+		b |= controlMoveRight
+	}
+	if control.Jump {
+		b |= controlJump
+	}
+	if control.Attack {
+		b |= controlAttack
+	}
+	return b
+}
+
+func DecodeControl(b byte) message.FighterControl {
+	return message.FighterControl{
+		MoveLeft:  b&controlMoveLeft != 0,
+		MoveRight: b&controlMoveRight != 0,
+		Jump:      b&controlJump != 0,
+		Attack:    b&controlAttack != 0,
+	}
+}
+
+func encodeSnapshot(buf []byte, snapshot message.GameSnapshot) []byte {
+	var flags byte
+	if snapshot.IsStart {
+		flags |= snapshotStart
+	}
+	if snapshot.IsEnd {
+		flags |= snapshotEnd
+	}
+
+	buf = binary.AppendUvarint(buf, snapshot.Tick)
+	buf = append(buf, flags)
+	buf = binary.AppendUvarint(buf, uint64(snapshot.Round))
+	buf = binary.AppendUvarint(buf, uint64(len(snapshot.Wins)))
+	for _, wins := range snapshot.Wins {
+		buf = binary.AppendUvarint(buf, uint64(wins))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(snapshot.Fighters)))
+
+	for _, f := range snapshot.Fighters {
+		buf = appendString(buf, f.ID)
+		buf = appendString(buf, f.CharacterName)
+		buf = appendString(buf, f.State)
+		buf = binary.AppendUvarint(buf, uint64(f.AnimationFrame))
+		buf = appendFloat(buf, f.HealthPoints)
+		if f.Specular {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		buf = appendRect(buf, f.HitBox)
+		buf = appendRect(buf, f.AttackBox)
+	}
+
+	return buf
+}
+
+func decodeSnapshot(r *reader) message.GameSnapshot {
+	snapshot := message.GameSnapshot{
+		Tick: r.uvarint(),
+	}
+	flags := r.byte()
+	snapshot.IsStart = flags&snapshotStart != 0
+	snapshot.IsEnd = flags&snapshotEnd != 0
+	snapshot.Round = int(r.uvarint())
+
+	wins := r.count(1)
+	snapshot.Wins = make([]int, 0, wins)
+	for i := 0; i < wins; i++ {
+		snapshot.Wins = append(snapshot.Wins, int(r.uvarint()))
+	}
+
+	count := r.count(minFighterSnapshotSize)
+	snapshot.Fighters = make([]message.FighterSnapshot, 0, count)
+	for i := 0; i < count; i++ {
+		snapshot.Fighters = append(snapshot.Fighters, message.FighterSnapshot{
+			ID:             r.string(),
+			CharacterName:  r.string(),
+			State:          r.string(),
+			AnimationFrame: int(r.uvarint()),
+			HealthPoints:   r.float(),
+			Specular:       r.byte() != 0,
+			HitBox:         r.rect(),
+			AttackBox:      r.rect(),
+		})
+	}
+
+	return snapshot
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendFloat(buf []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v)))
+}
+
+func appendRect(buf []byte, rect primitives.Rect) []byte {
+	buf = appendFloat(buf, rect.Pos.X)
+	buf = appendFloat(buf, rect.Pos.Y)
+	buf = appendFloat(buf, rect.Size.X)
+	return appendFloat(buf, rect.Size.Y)
+}
+
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) fail() {
+	if r.err == nil {
+		r.err = fmt.Errorf("truncated binary message")
+	}
+	r.buf = nil
+}
+
+func (r *reader) byte() byte {
+	if len(r.buf) < 1 {
+		r.fail()
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *reader) count(size int) int {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)/size) {
+		r.fail()
+		return 0
+	}
+	return int(n)
+}
+
+func (r *reader) string() string {
+	n := r.count(1)
+	s := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return s
+}
+
+func (r *reader) float() float64 {
+	if len(r.buf) < 4 {
+		r.fail()
+		return 0
+	}
+	v := math.Float32frombits(binary.LittleEndian.Uint32(r.buf))
+	r.buf = r.buf[4:]
+	return float64(v)
+}
+
+func (r *reader) rect() primitives.Rect {
+	return primitives.NewRect(r.float(), r.float(), r.float(), r.float())
+}
@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"webgl-app/internal/graphics/primitives"
+	"webgl-app/internal/net/message"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tests := []message.Message{
+		{
+			Type: message.PlayerInputMsg,
+			Data: message.FrameInput{Frame: 1234, Control: message.FighterControl{MoveRight: true, Attack: true}},
+		},
+		{
+			Type: message.ConfirmedInputsMsg,
+			Data: message.ConfirmedInputs{
+				Frame:    99,
+				Controls: []message.FighterControl{{MoveLeft: true, Jump: true}, {}},
+			},
+		},
+		{
+			Type: message.GameStateMsg,
+			Data: message.GameSnapshot{
+				Tick:    600,
+				Round:   2,
+				Wins:    []int{1, 0},
+				IsStart: true,
+				Fighters: []message.FighterSnapshot{
+					{
+						ID:             "p1",
+						CharacterName:  "knight",
+						State:          "attack",
+						AnimationFrame: 3,
+						HealthPoints:   75.5,
+						Specular:       true,
+						HitBox:         primitives.NewRect(10, 20, 30, 40),
+						AttackBox:      primitives.NewRect(40, 20, 15.25, 10),
+					},
+					{
+						ID:            "p2",
+						CharacterName: "knight",
+						State:         "idle",
+						HealthPoints:  100,
+						HitBox:        primitives.NewRect(-10.5, 0, 30, 40),
+					},
+				},
+			},
+		},
+	}
+
+	for _, msg := range tests {
+		data, err := Binary{}.Encode(msg)
+		if err != nil {
+			t.Fatalf("Encode(%s): %v", msg.Type, err)
+		}
+
+		got, err := Binary{}.Decode(data)
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", msg.Type, err)
+		}
+		if !reflect.DeepEqual(got, msg) {
+			t.Errorf("round trip of %s = %+v, want %+v", msg.Type, got, msg)
+		}
+	}
+}
+
+func TestBinaryDecodeRejectsTruncatedInput(t *testing.T) {
+	msg := message.Message{
+		Type: message.GameStateMsg,
+		Data: message.GameSnapshot{
+			Wins:     []int{0, 0},
+			Fighters: []message.FighterSnapshot{{ID: "p1"}, {ID: "p2"}},
+		},
+	}
+	data, err := Binary{}.Encode(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 1; n < len(data); n++ {
+		if _, err := (Binary{}).Decode(data[:n]); err == nil {
+			t.Errorf("Decode of %d/%d bytes succeeded", n, len(data))
+		}
+	}
+}
+
+func TestBinaryDecodeRejectsOversizedCounts(t *testing.T) {
+	confirmed := binary.AppendUvarint([]byte{tagConfirmedInputs, 0}, math.MaxUint64)
+
+	snapshot := []byte{tagGameState, 0, 0, 0, 0}
+	snapshot = binary.AppendUvarint(snapshot, 1000)
+	snapshot = append(snapshot, make([]byte, minFighterSnapshotSize)...)
+
+	name := []byte{tagGameState, 0, 0, 0, 0, 1}
+	name = binary.AppendUvarint(name, 1<<40)
+	name = append(name, make([]byte, minFighterSnapshotSize)...)
+
+	for _, data := range [][]byte{confirmed, snapshot, name} {
+		msg, err := Binary{}.Decode(data)
+		if err == nil {
+			t.Errorf("Decode(%v) succeeded", data)
+			continue
+		}
+
+		switch decoded := msg.Data.(type) {
+		case message.ConfirmedInputs:
+			if cap(decoded.Controls) > len(data) {
+				t.Errorf("allocated %d controls for %d bytes", cap(decoded.Controls), len(data))
+			}
+		case message.GameSnapshot:
+			if cap(decoded.Fighters) > 1 {
+				t.Errorf("allocated %d fighters for %d bytes", cap(decoded.Fighters), len(data))
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"webgl-app/internal/net/message"
+)
+
+const (
+	JSONName   = "json"
+	BinaryName = "binary"
+)
+
+type Codec interface {
+	Name() string
+	IsBinary() bool
+	Encode(msg message.Message) ([]byte, error)
+	Decode(data []byte) (message.Message, error)
+}
+
+func ByName(name string) (Codec, error) {
+	switch name {
+	case "", JSONName:
+		return JSON{}, nil
+	case BinaryName:
+		return Binary{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec: %s", name)
+	}
+}
+
+type JSON struct{}
+
+func (JSON) Name() string {
+	return JSONName
+}
+
+func (JSON) IsBinary() bool {
+	return false
+}
+
+func (JSON) Encode(msg message.Message) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (JSON) Decode(data []byte) (message.Message, error) {
+	var msg message.Message
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
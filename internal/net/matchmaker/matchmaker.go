@@ -0,0 +1,247 @@
+package matchmaker
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"webgl-app/internal/net/player"
+)
+
+type Settings struct {
+	Interval     time.Duration
+	BaseWindow   float64
+	WindowGrowth float64
+	MaxWindow    float64
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Interval:     time.Second,
+		BaseWindow:   100,
+		WindowGrowth: 10,
+		MaxWindow:    1000,
+	}
+}
+
+type Status struct {
+	InQueue       bool
+	Matched       bool
+	Position      int
+	QueueSize     int
+	Waited        time.Duration
+	EstimatedWait time.Duration
+}
+
+type entry struct {
+	player   *player.Player
+	rating   float64
+	joinedAt time.Time
+}
+
+type Matchmaker struct {
+	settings    Settings
+	queue       []*entry
+	averageWait time.Duration
+	onMatch     func(first *player.Player, second *player.Player)
+	onStatus    func(_player *player.Player, status Status)
+	stop        chan struct{}
+	mu          sync.Mutex
+}
+
+func NewMatchmaker(settings Settings, onMatch func(*player.Player, *player.Player), onStatus func(*player.Player, Status)) *Matchmaker {
+	return &Matchmaker{
+		settings: settings,
+		queue:    make([]*entry, 0),
+		onMatch:  onMatch,
+		onStatus: onStatus,
+	}
+}
+
+func (mm *Matchmaker) Start() {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if mm.stop != nil {
+		return
+	}
+	mm.stop = make(chan struct{})
+
+	go mm.run(mm.stop)
+}
+
+func (mm *Matchmaker) Stop() {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if mm.stop != nil {
+		close(mm.stop)
+		mm.stop = nil
+	}
+}
+
+func (mm *Matchmaker) Enqueue(_player *player.Player, rating float64) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if mm.indexOf(_player.ID()) >= 0 {
+		return fmt.Errorf("player is already in the queue")
+	}
+
+	mm.queue = append(mm.queue, &entry{
+		player:   _player,
+		rating:   rating,
+		joinedAt: time.Now(),
+	})
+
+	return nil
+}
+
+func (mm *Matchmaker) Dequeue(playerID string) bool {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	index := mm.indexOf(playerID)
+	if index < 0 {
+		return false
+	}
+	mm.queue = append(mm.queue[:index], mm.queue[index+1:]...)
+
+	return true
+}
+
+func (mm *Matchmaker) Status(playerID string) Status {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	index := mm.indexOf(playerID)
+	if index < 0 {
+		return Status{}
+	}
+
+	return mm.statusAt(index, time.Now())
+}
+
+func (mm *Matchmaker) run(stop chan struct{}) {
+	ticker := time.NewTicker(mm.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			mm.matchPlayers()
+		}
+	}
+}
+
+func (mm *Matchmaker) matchPlayers() {
+	now := time.Now()
+
+	mm.mu.Lock()
+	mm.removeUnavailable()
+
+	pairs := make([][2]*player.Player, 0)
+	matched := make(map[int]bool)
+	for i, first := range mm.queue {
+		if matched[i] {
+			continue
+		}
+
+		best := -1
+		bestDiff := math.Inf(1)
+		for j := i + 1; j < len(mm.queue); j++ {
+			if matched[j] {
+				continue
+			}
+
+			second := mm.queue[j]
+			diff := math.Abs(first.rating - second.rating)
+			window := math.Max(mm.window(first, now), mm.window(second, now))
+			if diff <= window && diff < bestDiff && !player.SameName(first.player.GetName(), second.player.GetName()) {
+				best = j
+				bestDiff = diff
+			}
+		}
+
+		if best >= 0 {
+			matched[i] = true
+			matched[best] = true
+			mm.recordWait(now.Sub(first.joinedAt))
+			mm.recordWait(now.Sub(mm.queue[best].joinedAt))
+			pairs = append(pairs, [2]*player.Player{first.player, mm.queue[best].player})
+		}
+	}
+
+	remaining := make([]*entry, 0, len(mm.queue)-len(matched))
+	for i, e := range mm.queue {
+		if !matched[i] {
+			remaining = append(remaining, e)
+		}
+	}
+	mm.queue = remaining
+
+	statuses := make(map[*player.Player]Status, len(mm.queue))
+	for i, e := range mm.queue {
+		statuses[e.player] = mm.statusAt(i, now)
+	}
+	mm.mu.Unlock()
+
+	for _, pair := range pairs {
+		mm.onMatch(pair[0], pair[1])
+	}
+	for _player, status := range statuses {
+		mm.onStatus(_player, status)
+	}
+}
+
+func (mm *Matchmaker) removeUnavailable() {
+	available := make([]*entry, 0, len(mm.queue))
+	for _, e := range mm.queue {
+		if e.player.IsConnected() && e.player.GetRoomID() == "" {
+			available = append(available, e)
+		}
+	}
+	mm.queue = available
+}
+
+func (mm *Matchmaker) window(e *entry, now time.Time) float64 {
+	window := mm.settings.BaseWindow + mm.settings.WindowGrowth*now.Sub(e.joinedAt).Seconds()
+	return math.Min(window, mm.settings.MaxWindow)
+}
+
+func (mm *Matchmaker) recordWait(wait time.Duration) {
+	if mm.averageWait == 0 {
+		mm.averageWait = wait
+		return
+	}
+	mm.averageWait = (mm.averageWait*4 + wait) / 5
+}
+
+func (mm *Matchmaker) statusAt(index int, now time.Time) Status {
+	e := mm.queue[index]
+	waited := now.Sub(e.joinedAt)
+
+	estimated := mm.averageWait - waited
+	if estimated < 0 {
+		estimated = 0
+	}
+
+	return Status{
+		InQueue:       true,
+		Position:      index + 1,
+		QueueSize:     len(mm.queue),
+		Waited:        waited,
+		EstimatedWait: estimated,
+	}
+}
+
+func (mm *Matchmaker) indexOf(playerID string) int {
+	for i, e := range mm.queue {
+		if e.player.ID() == playerID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package matchmaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"webgl-app/internal/net/codec"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+
+	"github.com/gorilla/websocket"
+)
+
+func connectedPlayer(t *testing.T, name string) *player.Player {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	_player := player.NewPlayer(name, player.DefaultOptions())
+	if err := _player.Attach(<-conns, codec.JSON{}, message.Message{Type: message.WelcomeMsg}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(_player.Close)
+
+	return _player
+}
+
+func newTestMatchmaker() (*Matchmaker, *[][2]*player.Player) {
+	pairs := make([][2]*player.Player, 0)
+	mm := NewMatchmaker(DefaultSettings(), func(first *player.Player, second *player.Player) {
+		pairs = append(pairs, [2]*player.Player{first, second})
+	}, func(*player.Player, Status) {})
+
+	return mm, &pairs
+}
+
+func TestMatchPlayersPairsCloseRatings(t *testing.T) {
+	mm, pairs := newTestMatchmaker()
+	first, second, far := connectedPlayer(t, "first"), connectedPlayer(t, "second"), connectedPlayer(t, "far")
+
+	mm.Enqueue(first, 1500)
+	mm.Enqueue(far, 2400)
+	mm.Enqueue(second, 1550)
+	mm.matchPlayers()
+
+	if len(*pairs) != 1 || (*pairs)[0][0] != first || (*pairs)[0][1] != second {
+		t.Fatalf("pairs = %v, want first with second", *pairs)
+	}
+	if status := mm.Status(far.ID()); !status.InQueue || status.QueueSize != 1 {
+		t.Fatalf("unmatched player status = %+v", status)
+	}
+	if mm.Status(first.ID()).InQueue || mm.Status(second.ID()).InQueue {
+		t.Fatal("matched players are still queued")
+	}
+}
+
+func TestMatchPlayersSkipsUnavailablePlayers(t *testing.T) {
+	mm, pairs := newTestMatchmaker()
+	inRoom, offline, waiting := connectedPlayer(t, "in-room"), player.NewPlayer("offline", player.DefaultOptions()), connectedPlayer(t, "waiting")
+
+	mm.Enqueue(inRoom, 1500)
+	mm.Enqueue(offline, 1500)
+	mm.Enqueue(waiting, 1500)
+	inRoom.SetRoomID("ROOM01")
+	mm.matchPlayers()
+
+	if len(*pairs) != 0 {
+		t.Fatalf("unavailable players were matched: %v", *pairs)
+	}
+	if mm.Status(inRoom.ID()).InQueue || mm.Status(offline.ID()).InQueue {
+		t.Fatal("unavailable players were kept in the queue")
+	}
+	if !mm.Status(waiting.ID()).InQueue {
+		t.Fatal("available player was dropped from the queue")
+	}
+}
+
+func TestEnqueueRejectsDuplicates(t *testing.T) {
+	mm, _ := newTestMatchmaker()
+	_player := connectedPlayer(t, "first")
+
+	if err := mm.Enqueue(_player, 1500); err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.Enqueue(_player, 1500); err == nil {
+		t.Fatal("player was queued twice")
+	}
+	if !mm.Dequeue(_player.ID()) || mm.Dequeue(_player.ID()) {
+		t.Fatal("Dequeue did not remove the player exactly once")
+	}
+}
@@ -8,6 +8,8 @@ type MessageType string
 
 const (
 	ErrorMsg            MessageType = "error"
+	HelloMsg            MessageType = "hello"
+	WelcomeMsg          MessageType = "welcome"
 	CreateRoomMsg       MessageType = "create_room"
 	JoinRoomMsg         MessageType = "join_room"
 	LeaveRoomMsg        MessageType = "leave_room"
@@ -19,6 +21,28 @@ const (
 	PlayerJoinMsg       MessageType = "player_join"
 	RoomClosedMsg       MessageType = "room_closed"
 	GameStateMsg        MessageType = "game_state"
+	PlayerInputMsg      MessageType = "player_input"
+	ConfirmedInputsMsg  MessageType = "confirmed_inputs"
+	ResumeGameMsg       MessageType = "resume_game"
+	SpectateRoomMsg     MessageType = "spectate_room"
+	ListRoomsMsg        MessageType = "list_rooms"
+	UnsubscribeRoomsMsg MessageType = "unsubscribe_rooms"
+	RoomListUpdateMsg   MessageType = "room_list_update"
+	QueueJoinMsg        MessageType = "queue_join"
+	QueueLeaveMsg       MessageType = "queue_leave"
+	QueueStatusMsg      MessageType = "queue_status"
+	ChatMessageMsg      MessageType = "chat_message"
+	ChatHistoryMsg      MessageType = "chat_history"
+	SetProfileMsg       MessageType = "set_profile"
+	KickPlayerMsg       MessageType = "kick_player"
+	MutePlayerMsg       MessageType = "mute_player"
+	LockRoomMsg         MessageType = "lock_room"
+	SetRoomPasswordMsg  MessageType = "set_room_password"
+	TransferOwnerMsg    MessageType = "transfer_owner"
+	RoomEventMsg        MessageType = "room_event"
+	SetReadyMsg         MessageType = "set_ready"
+	TimeSyncMsg         MessageType = "time_sync"
+	RoundEndMsg         MessageType = "round_end"
 )
 
 type Message struct {
@@ -26,18 +50,134 @@ type Message struct {
 	Data interface{}
 }
 
-type PlayerInfo struct {
-	ID   string
+type Capabilities struct {
+	Codecs      []string
+	Compression []string
+	Features    []string
+}
+
+type Hello struct {
+	ProtocolVersion int
+	BuildHash       string
+	Capabilities    Capabilities
+	SessionToken    string
+}
+
+type Welcome struct {
+	ProtocolVersion int
+	BuildHash       string
+	Codec           string
+	Compression     string
+	Features        []string
+	PlayerID        string
+	SessionToken    string
+	Resumed         bool
+}
+
+type Profile struct {
 	Name string
 }
 
+type PlayerInfo struct {
+	ID              string
+	Name            string
+	Rating          float64
+	RatingDeviation float64
+}
+
 type RoomInfo struct {
-	ID           string
+	ID              string
+	Status          string
+	OwnerId         string
+	PlayersCount    int
+	MaxPlayers      int
+	NeedPlayers     int
+	SpectatorsCount int
+	MaxSpectators   int
+	Public          bool
+	Locked          bool
+	HasPassword     bool
+	BestOf          int
+	ReadyPlayers    []string
+	MutedPlayers    []string
+	Players         []PlayerInfo
+	Spectators      []PlayerInfo
+}
+
+type JoinRoomRequest struct {
+	RoomID   string
+	Password string
+}
+
+type KickRequest struct {
+	PlayerID   string
+	BanSeconds int
+}
+
+type MuteRequest struct {
+	PlayerID string
+	Seconds  int
+}
+
+type RoomEventKind string
+
+const (
+	PlayerKickedEvent    RoomEventKind = "player_kicked"
+	PlayerBannedEvent    RoomEventKind = "player_banned"
+	PlayerMutedEvent     RoomEventKind = "player_muted"
+	PlayerUnmutedEvent   RoomEventKind = "player_unmuted"
+	RoomLockedEvent      RoomEventKind = "room_locked"
+	RoomUnlockedEvent    RoomEventKind = "room_unlocked"
+	PasswordSetEvent     RoomEventKind = "password_set"
+	PasswordClearedEvent RoomEventKind = "password_cleared"
+	OwnerChangedEvent    RoomEventKind = "owner_changed"
+)
+
+type RoomEvent struct {
+	Kind       RoomEventKind
+	ActorID    string
+	PlayerID   string
+	PlayerName string
+}
+
+type QueueStatus struct {
+	InQueue              bool
+	Matched              bool
+	Position             int
+	QueueSize            int
+	WaitedSeconds        int
+	EstimatedWaitSeconds int
+}
+
+type RoomFilter struct {
 	Status       string
-	OwnerId      string
-	PlayersCount int
-	MaxPlayers   int
-	NeedPlayers  int
+	MinFreeSlots int
+}
+
+type ListRoomsRequest struct {
+	Filter    RoomFilter
+	Page      int
+	PageSize  int
+	Subscribe bool
+}
+
+type RoomList struct {
+	Rooms    []RoomInfo
+	Page     int
+	PageSize int
+	Total    int
+}
+
+type RoomListUpdate struct {
+	Room    RoomInfo
+	Removed bool
+}
+
+type ChatMessage struct {
+	PlayerID string
+	Name     string
+	Text     string
+	Time     int64
 }
 
 type FighterControl struct {
@@ -55,6 +195,77 @@ type FighterInfo struct {
 	Control       FighterControl
 }
 
+type FrameInput struct {
+	Frame   uint64
+	Control FighterControl
+}
+
+type ConfirmedInputs struct {
+	Frame    uint64
+	Controls []FighterControl
+}
+
 type StartGameData struct {
+	MatchID           string
+	Level             string
+	Seed              int64
 	FightersPositions map[string]int
+	PlayerNames       map[string]string
+	InputDelay        int
+	RollbackWindow    int
+	BestOf            int
+	ServerTime        int64
+	StartAt           int64
+	Countdown         int64
+}
+
+type ErrorCode string
+
+const (
+	RateLimitedError ErrorCode = "rate_limited"
+	RoomLimitError   ErrorCode = "room_limit"
+	NoMatchError     ErrorCode = "no_match"
+)
+
+type Error struct {
+	Code       ErrorCode
+	Message    string
+	RetryAfter int64
+}
+
+type RoundResult struct {
+	Round         int
+	WinnerID      string
+	Wins          map[string]int
+	MatchWinnerID string
+}
+
+type TimeSync struct {
+	ClientTime int64
+	ServerTime int64
+}
+
+type FighterSnapshot struct {
+	ID             string
+	CharacterName  string
+	State          string
+	AnimationFrame int
+	HealthPoints   float64
+	Specular       bool
+	HitBox         primitives.Rect
+	AttackBox      primitives.Rect
+}
+
+type GameSnapshot struct {
+	Tick     uint64
+	Round    int
+	Wins     []int
+	IsStart  bool
+	IsEnd    bool
+	Fighters []FighterSnapshot
+}
+
+type ResumeGameData struct {
+	StartGame StartGameData
+	Inputs    []ConfirmedInputs
 }
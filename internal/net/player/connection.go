@@ -0,0 +1,109 @@
+package player
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+	"webgl-app/internal/net/codec"
+	"webgl-app/internal/net/message"
+
+	"github.com/gorilla/websocket"
+)
+
+type outbound struct {
+	msgType message.MessageType
+	data    []byte
+}
+
+type connection struct {
+	ws        *websocket.Conn
+	codec     codec.Codec
+	playerID  string
+	options   Options
+	queue     chan outbound
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newConnection(ws *websocket.Conn, c codec.Codec, playerID string, options Options) *connection {
+	conn := &connection{
+		ws:       ws,
+		codec:    c,
+		playerID: playerID,
+		options:  options,
+		queue:    make(chan outbound, options.SendQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	go conn.writeLoop()
+
+	return conn
+}
+
+func (c *connection) send(msg message.Message) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+
+	data, err := c.codec.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if msg.Type == message.GameStateMsg && len(c.queue) >= cap(c.queue)/2 {
+		return nil
+	}
+
+	select {
+	case c.queue <- outbound{msgType: msg.Type, data: data}:
+		return nil
+	default:
+		slog.Warn("Player is too slow, disconnecting", "player", c.playerID)
+		c.close()
+		return ErrSlowConsumer
+	}
+}
+
+func (c *connection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+func (c *connection) writeLoop() {
+	wsType := websocket.TextMessage
+	if c.codec.IsBinary() {
+		wsType = websocket.BinaryMessage
+	}
+
+	var pings <-chan time.Time
+	if c.options.PingInterval > 0 {
+		ticker := time.NewTicker(c.options.PingInterval)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-pings:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.options.WriteTimeout)); err != nil {
+				slog.Warn("Failed to ping player", "player", c.playerID, "err", err)
+				c.close()
+				return
+			}
+		case item := <-c.queue:
+			c.ws.SetWriteDeadline(time.Now().Add(c.options.WriteTimeout))
+
+			if err := c.ws.WriteMessage(wsType, item.data); err != nil {
+				slog.Warn("Failed to send message", "type", item.msgType, "player", c.playerID, "err", err)
+				c.close()
+				return
+			}
+		}
+	}
+}
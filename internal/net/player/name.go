@@ -0,0 +1,43 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MinNameLength = 3
+	MaxNameLength = 16
+)
+
+func DefaultName(id string) string {
+	suffix := strings.ReplaceAll(id, "-", "")
+	if len(suffix) > 4 {
+		suffix = suffix[:4]
+	}
+
+	return "Player-" + strings.ToUpper(suffix)
+}
+
+func ValidateName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	length := utf8.RuneCountInString(name)
+	if length < MinNameLength || length > MaxNameLength {
+		return "", fmt.Errorf("name must be between %d and %d characters long", MinNameLength, MaxNameLength)
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '_' && r != '-' {
+			return "", fmt.Errorf("name may only contain letters, digits, spaces, '_' and '-'")
+		}
+	}
+
+	return name, nil
+}
+
+func SameName(first string, second string) bool {
+	return strings.EqualFold(first, second)
+}
@@ -1,30 +1,68 @@
 package player
 
 import (
+	"errors"
 	"sync"
+	"time"
+	"webgl-app/internal/net/codec"
 	"webgl-app/internal/net/message"
+	"webgl-app/internal/rating"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+type Options struct {
+	SendQueueSize int
+	WriteTimeout  time.Duration
+	PingInterval  time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		SendQueueSize: 256,
+		WriteTimeout:  10 * time.Second,
+		PingInterval:  15 * time.Second,
+	}
+}
+
+var (
+	ErrClosed       = errors.New("player connection is closed")
+	ErrSlowConsumer = errors.New("player send queue is full")
+)
+
 type Player struct {
-	conn   *websocket.Conn
-	id     string
-	name   string
-	roomID string
-	mu     sync.RWMutex
+	conn    *connection
+	id      string
+	name    string
+	roomID  string
+	rating  rating.Rating
+	options Options
+	mu      sync.RWMutex
 }
 
-func NewPlayer(conn *websocket.Conn, name string) *Player {
+func NewPlayer(name string, options Options) *Player {
+	id := uuid.New().String()
+	if name == "" {
+		name = DefaultName(id)
+	}
+
 	return &Player{
-		conn:   conn,
-		id:     uuid.New().String(),
-		name:   name,
-		roomID: "",
+		id:      id,
+		name:    name,
+		roomID:  "",
+		rating:  rating.NewRating(),
+		options: options,
 	}
 }
 
+func RestorePlayer(id string, name string, options Options) *Player {
+	_player := NewPlayer(name, options)
+	_player.id = id
+
+	return _player
+}
+
 func (p *Player) ID() string {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -37,8 +75,10 @@ func (p *Player) PlayerInfo() message.PlayerInfo {
 	defer p.mu.Unlock()
 
 	return message.PlayerInfo{
-		ID:   p.id,
-		Name: p.name,
+		ID:              p.id,
+		Name:            p.name,
+		Rating:          p.rating.Rating,
+		RatingDeviation: p.rating.Deviation,
 	}
 }
 
@@ -56,6 +96,20 @@ func (p *Player) GetName() string {
 	return p.name
 }
 
+func (p *Player) SetRating(r rating.Rating) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rating = r
+}
+
+func (p *Player) GetRating() rating.Rating {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.rating
+}
+
 func (p *Player) SetRoomID(roomID string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -70,9 +124,76 @@ func (p *Player) GetRoomID() string {
 	return p.roomID
 }
 
-func (p *Player) Send(msg message.Message) {
+func (p *Player) Attach(conn *websocket.Conn, c codec.Codec, greeting message.Message) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	p.conn.WriteJSON(msg)
+	newConn := newConnection(conn, c, p.id, p.options)
+	if err := newConn.send(greeting); err != nil {
+		newConn.close()
+		return err
+	}
+
+	if p.conn != nil {
+		p.conn.close()
+	}
+	p.conn = newConn
+
+	return nil
+}
+
+func (p *Player) Detach(conn *websocket.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil || p.conn.ws != conn {
+		return false
+	}
+
+	p.conn.close()
+	p.conn = nil
+
+	return true
+}
+
+func (p *Player) IsConnected() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.conn != nil
+}
+
+func (p *Player) Send(msg message.Message) error {
+	p.mu.RLock()
+	conn := p.conn
+	p.mu.RUnlock()
+
+	if conn == nil {
+		return ErrClosed
+	}
+
+	return conn.send(msg)
+}
+
+func (p *Player) Close() {
+	p.mu.RLock()
+	conn := p.conn
+	p.mu.RUnlock()
+
+	if conn != nil {
+		conn.close()
+	}
+}
+
+func (p *Player) Done() <-chan struct{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.conn == nil {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+
+	return p.conn.done
 }
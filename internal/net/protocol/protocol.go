@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"fmt"
+	"webgl-app/internal/net/codec"
+	"webgl-app/internal/net/message"
+)
+
+const Version = 2
+
+const (
+	CompressionDeflate = "permessage-deflate"
+	FeatureRollback    = "rollback"
+)
+
+var BuildHash = "dev"
+
+func Capabilities() message.Capabilities {
+	return message.Capabilities{
+		Codecs:      []string{codec.BinaryName, codec.JSONName},
+		Compression: []string{CompressionDeflate},
+		Features:    []string{FeatureRollback},
+	}
+}
+
+func NewHello(preferredCodec string) message.Hello {
+	capabilities := Capabilities()
+	capabilities.Codecs = prefer(capabilities.Codecs, preferredCodec)
+
+	return message.Hello{
+		ProtocolVersion: Version,
+		BuildHash:       BuildHash,
+		Capabilities:    capabilities,
+	}
+}
+
+func Negotiate(hello message.Hello, compressed bool) (message.Welcome, error) {
+	if hello.ProtocolVersion != Version {
+		return message.Welcome{}, fmt.Errorf("incompatible protocol version %d (server uses %d), please reload the page", hello.ProtocolVersion, Version)
+	}
+
+	capabilities := Capabilities()
+
+	codecName := first(hello.Capabilities.Codecs, capabilities.Codecs)
+	if codecName == "" {
+		return message.Welcome{}, fmt.Errorf("no supported codec, please reload the page")
+	}
+
+	welcome := message.Welcome{
+		ProtocolVersion: Version,
+		BuildHash:       BuildHash,
+		Codec:           codecName,
+		Features:        intersect(hello.Capabilities.Features, capabilities.Features),
+	}
+	if compressed {
+		welcome.Compression = first(hello.Capabilities.Compression, capabilities.Compression)
+	}
+
+	return welcome, nil
+}
+
+func prefer(values []string, preferred string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == preferred {
+			result = append(result, v)
+		}
+	}
+	for _, v := range values {
+		if v != preferred {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func first(wanted, supported []string) string {
+	for _, w := range wanted {
+		for _, s := range supported {
+			if w == s {
+				return w
+			}
+		}
+	}
+	return ""
+}
+
+func intersect(wanted, supported []string) []string {
+	result := make([]string, 0)
+	for _, w := range wanted {
+		for _, s := range supported {
+			if w == s {
+				result = append(result, w)
+				break
+			}
+		}
+	}
+	return result
+}
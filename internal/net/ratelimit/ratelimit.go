@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+	"webgl-app/internal/net/message"
+)
+
+const defaultBucket message.MessageType = ""
+
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Settings struct {
+	MaxMessageSize      int64
+	MaxConnectionsPerIP int
+	MaxViolations       int
+	ViolationDecay      time.Duration
+	Default             Limit
+	PerType             map[message.MessageType]Limit
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		MaxMessageSize:      16 * 1024,
+		MaxConnectionsPerIP: 8,
+		MaxViolations:       20,
+		ViolationDecay:      5 * time.Second,
+		Default:             Limit{Rate: 10, Burst: 40},
+		PerType: map[message.MessageType]Limit{
+			message.PlayerInputMsg:  {Rate: 120, Burst: 240},
+			message.CreateRoomMsg:   {Rate: 0.2, Burst: 3},
+			message.JoinRoomMsg:     {Rate: 1, Burst: 5},
+			message.SpectateRoomMsg: {Rate: 1, Burst: 5},
+			message.ChatMessageMsg:  {Rate: 2, Burst: 10},
+			message.SetProfileMsg:   {Rate: 0.5, Burst: 5},
+			message.ListRoomsMsg:    {Rate: 2, Burst: 10},
+			message.TimeSyncMsg:     {Rate: 2, Burst: 10},
+		},
+	}
+}
+
+type Bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func NewBucket(limit Limit) *Bucket {
+	return &Bucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *Bucket) Allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if b.limit.Rate <= 0 {
+		return 0, false
+	}
+
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second)), false
+}
+
+type Limiter struct {
+	settings      Settings
+	buckets       map[message.MessageType]*Bucket
+	violations    float64
+	lastViolation time.Time
+	mu            sync.Mutex
+}
+
+func NewLimiter(settings Settings) *Limiter {
+	return &Limiter{
+		settings: settings,
+		buckets:  make(map[message.MessageType]*Bucket),
+	}
+}
+
+func (l *Limiter) Allow(msgType message.MessageType) (time.Duration, bool) {
+	limit, exists := l.settings.PerType[msgType]
+	if !exists {
+		limit = l.settings.Default
+		msgType = defaultBucket
+	}
+	if limit.Burst <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	bucket, exists := l.buckets[msgType]
+	if !exists {
+		bucket = NewBucket(limit)
+		l.buckets[msgType] = bucket
+	}
+	l.mu.Unlock()
+
+	return bucket.Allow()
+}
+
+func (l *Limiter) AddViolation() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.settings.ViolationDecay > 0 && !l.lastViolation.IsZero() {
+		decayed := float64(now.Sub(l.lastViolation)) / float64(l.settings.ViolationDecay)
+		l.violations = math.Max(0, l.violations-decayed)
+	}
+	l.lastViolation = now
+
+	l.violations++
+	return int(math.Ceil(l.violations))
+}
+
+type Connections struct {
+	max    int
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func NewConnections(max int) *Connections {
+	return &Connections{
+		max:    max,
+		counts: make(map[string]int),
+	}
+}
+
+func (c *Connections) Acquire(ip string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.max > 0 && c.counts[ip] >= c.max {
+		return false
+	}
+
+	c.counts[ip]++
+	return true
+}
+
+func (c *Connections) Release(ip string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[ip]--
+	if c.counts[ip] <= 0 {
+		delete(c.counts, ip)
+	}
+}
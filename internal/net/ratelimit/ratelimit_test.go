@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"webgl-app/internal/net/message"
+)
+
+func TestViolationsDecay(t *testing.T) {
+	settings := DefaultSettings()
+	settings.ViolationDecay = 20 * time.Millisecond
+	limiter := NewLimiter(settings)
+
+	for i := 1; i <= 3; i++ {
+		if count := limiter.AddViolation(); count != i {
+			t.Fatalf("violation %d counted as %d", i, count)
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if count := limiter.AddViolation(); count != 1 {
+		t.Fatalf("violations did not decay, count is %d", count)
+	}
+}
+
+func TestViolationsWithoutDecay(t *testing.T) {
+	settings := DefaultSettings()
+	settings.ViolationDecay = 0
+	limiter := NewLimiter(settings)
+
+	limiter.AddViolation()
+	time.Sleep(10 * time.Millisecond)
+	if count := limiter.AddViolation(); count != 2 {
+		t.Fatalf("count is %d, want 2", count)
+	}
+}
+
+func TestUnknownTypesShareDefaultBucket(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Default = Limit{Rate: 0.001, Burst: 5}
+	limiter := NewLimiter(settings)
+
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		if _, ok := limiter.Allow(message.MessageType(fmt.Sprintf("made_up_%d", i))); ok {
+			allowed++
+		}
+	}
+	if allowed != settings.Default.Burst {
+		t.Fatalf("%d rotating unknown types were allowed, want %d", allowed, settings.Default.Burst)
+	}
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("limiter holds %d buckets, want 1", len(limiter.buckets))
+	}
+
+	if _, ok := limiter.Allow(message.ChatMessageMsg); !ok {
+		t.Fatal("a type with its own limit was throttled by the default bucket")
+	}
+}
@@ -0,0 +1,74 @@
+package replayhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"webgl-app/internal/replay"
+)
+
+const PathPrefix = "/replays/"
+
+type ReplayHandler struct {
+	store *replay.Store
+}
+
+func NewReplayHandler(store *replay.Store) *ReplayHandler {
+	return &ReplayHandler{
+		store: store,
+	}
+}
+
+func (h *ReplayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matchID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, PathPrefix), replay.FileExtension)
+	if matchID == "" {
+		h.list(w)
+		return
+	}
+
+	h.download(w, r, matchID)
+}
+
+func (h *ReplayHandler) list(w http.ResponseWriter) {
+	infos, err := h.store.List()
+	if err != nil {
+		slog.Error("Failed to list replays", "err", err)
+		http.Error(w, "failed to list replays", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+func (h *ReplayHandler) download(w http.ResponseWriter, r *http.Request, matchID string) {
+	file, err := h.store.Open(matchID)
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to open replay", "err", err)
+		http.Error(w, "failed to open replay", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		http.Error(w, "failed to open replay", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+matchID+replay.FileExtension+`"`)
+	http.ServeContent(w, r, matchID+replay.FileExtension, stat.ModTime(), file)
+}
@@ -1,8 +1,15 @@
 package room
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
+	"time"
+	"webgl-app/internal/game/match"
+	"webgl-app/internal/game/rollback"
+	"webgl-app/internal/net/chat"
 	"webgl-app/internal/net/message"
 	"webgl-app/internal/net/player"
 )
@@ -15,27 +22,80 @@ const (
 	InGame  RoomStatus = "In game"
 )
 
+type OwnerLeavePolicy string
+
+const (
+	CloseRoom    OwnerLeavePolicy = "close"
+	MigrateOwner OwnerLeavePolicy = "migrate"
+)
+
+const (
+	DefaultInputDelay     = 2
+	MaxInputDelay         = 10
+	DefaultRollbackWindow = 8
+	MaxRollbackWindow     = 30
+	DefaultMaxSpectators  = 8
+	MaxSpectatorsLimit    = 64
+	ChatHistorySize       = 50
+	MaxPasswordLength     = 64
+	MaxBanDuration        = 24 * time.Hour
+	MaxMuteDuration       = 24 * time.Hour
+	DefaultBestOf         = 1
+	MaxBestOf             = 9
+	InputClockTolerance   = 30
+)
+
 type RoomSettings struct {
-	MaxPlayers  int
-	NeedPlayers int
+	Public         bool
+	Record         bool
+	Password       string
+	OwnerLeave     OwnerLeavePolicy
+	MaxPlayers     int
+	NeedPlayers    int
+	MaxSpectators  int
+	InputDelay     int
+	RollbackWindow int
+	BestOf         int
 }
 
 type Room struct {
-	id       string
-	status   RoomStatus
-	settings RoomSettings
-	players  map[string]*player.Player
-	ownerID  string
-	mu       sync.Mutex
+	id         string
+	status     RoomStatus
+	settings   RoomSettings
+	players    map[string]*player.Player
+	spectators map[string]*player.Player
+	ownerID    string
+	joinedAt   map[string]time.Time
+	ready      map[string]bool
+	locked     bool
+	bans       map[string]time.Time
+	mutes      *chat.MuteList
+	createdAt  time.Time
+	match      *match.Match
+	inputs     *rollback.InputQueue
+	history    []message.ConfirmedInputs
+	lastInput  map[string]uint64
+	violations map[string]int
+	chat       []message.ChatMessage
+	gameData   message.StartGameData
+	stop       chan struct{}
+	mu         sync.Mutex
 }
 
 func NewRoom(roomCode string, settings RoomSettings) *Room {
 	return &Room{
-		id:       roomCode,
-		status:   Waiting,
-		settings: settings,
-		players:  make(map[string]*player.Player),
-		ownerID:  "",
+		id:         roomCode,
+		status:     Waiting,
+		settings:   settings,
+		players:    make(map[string]*player.Player),
+		spectators: make(map[string]*player.Player),
+		ownerID:    "",
+		joinedAt:   make(map[string]time.Time),
+		ready:      make(map[string]bool),
+		bans:       make(map[string]time.Time),
+		mutes:      chat.NewMuteList(),
+		createdAt:  time.Now(),
+		chat:       make([]message.ChatMessage, 0),
 	}
 }
 
@@ -51,13 +111,42 @@ func (r *Room) RoomInfo() message.RoomInfo {
 	defer r.mu.Unlock()
 
 	return message.RoomInfo{
-		ID:           r.id,
-		Status:       string(r.status),
-		OwnerId:      r.ownerID,
-		PlayersCount: len(r.players),
-		MaxPlayers:   r.settings.MaxPlayers,
-		NeedPlayers:  r.settings.NeedPlayers,
+		ID:              r.id,
+		Status:          string(r.status),
+		OwnerId:         r.ownerID,
+		PlayersCount:    len(r.players),
+		MaxPlayers:      r.settings.MaxPlayers,
+		NeedPlayers:     r.settings.NeedPlayers,
+		SpectatorsCount: len(r.spectators),
+		MaxSpectators:   r.settings.MaxSpectators,
+		Public:          r.settings.Public,
+		Locked:          r.locked,
+		HasPassword:     r.settings.Password != "",
+		BestOf:          r.settings.BestOf,
+		ReadyPlayers:    r.readyPlayers(),
+		MutedPlayers:    r.mutes.Muted(),
+		Players:         membersInfo(r.players),
+		Spectators:      membersInfo(r.spectators),
+	}
+}
+
+func membersInfo(members map[string]*player.Player) []message.PlayerInfo {
+	infos := make([]message.PlayerInfo, 0, len(members))
+	for _, p := range members {
+		infos = append(infos, p.PlayerInfo())
 	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+func (r *Room) GetCreatedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.createdAt
 }
 
 func (r *Room) SetStatus(status RoomStatus) {
@@ -81,38 +170,210 @@ func (r *Room) GetSettings() RoomSettings {
 	return r.settings
 }
 
+func (r *Room) SetLocked(locked bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locked = locked
+}
+
+func (r *Room) IsLocked() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.locked
+}
+
+func (r *Room) SetPassword(password string) error {
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("password is longer than %d characters", MaxPasswordLength)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings.Password = password
+	return nil
+}
+
+func (r *Room) CheckPassword(password string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.settings.Password == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(r.settings.Password), []byte(password)) == 1
+}
+
+func (r *Room) Ban(playerID string, duration time.Duration) {
+	if duration > MaxBanDuration {
+		duration = MaxBanDuration
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bans[playerID] = time.Now().Add(duration)
+}
+
+func (r *Room) IsBanned(playerID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.isBanned(playerID)
+}
+
+func (r *Room) isBanned(playerID string) bool {
+	until, exists := r.bans[playerID]
+	if !exists {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(r.bans, playerID)
+		return false
+	}
+
+	return true
+}
+
+func (r *Room) Mute(playerID string, duration time.Duration) {
+	if duration > MaxMuteDuration {
+		duration = MaxMuteDuration
+	}
+
+	r.mutes.Mute(playerID, duration)
+}
+
+func (r *Room) Unmute(playerID string) {
+	r.mutes.Unmute(playerID)
+}
+
+func (r *Room) Mutes() *chat.MuteList {
+	return r.mutes
+}
+
+func (r *Room) checkAdmission(_player *player.Player) error {
+	if r.isBanned(_player.ID()) {
+		return fmt.Errorf("you are banned from this room")
+	}
+	if r.locked {
+		return fmt.Errorf("room is locked")
+	}
+
+	return nil
+}
+
 func (r *Room) AddPlayer(_player *player.Player) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if _, exists := r.spectators[_player.ID()]; exists {
+		return fmt.Errorf("player is already in the room")
+	}
+
+	if err := r.checkAdmission(_player); err != nil {
+		return err
+	}
 	if r.status == InGame {
 		return fmt.Errorf("there is a game going on in the room now")
 	}
 	if len(r.players) >= r.settings.MaxPlayers {
 		return fmt.Errorf("room is full")
 	}
+	if r.isNameTaken(_player.GetName(), _player.ID()) {
+		return fmt.Errorf("name %s is already taken in this room", _player.GetName())
+	}
 
 	r.players[_player.ID()] = _player
+	r.joinedAt[_player.ID()] = time.Now()
 	r.UpdateStatus(false)
 
 	return nil
 }
 
+func (r *Room) AddSpectator(_player *player.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.players[_player.ID()]; exists {
+		return fmt.Errorf("player is already in the room")
+	}
+	if err := r.checkAdmission(_player); err != nil {
+		return err
+	}
+	if len(r.spectators) >= r.settings.MaxSpectators {
+		return fmt.Errorf("room has no free spectator slots")
+	}
+	if r.isNameTaken(_player.GetName(), _player.ID()) {
+		return fmt.Errorf("name %s is already taken in this room", _player.GetName())
+	}
+
+	r.spectators[_player.ID()] = _player
+	r.joinedAt[_player.ID()] = time.Now()
+
+	return nil
+}
+
 func (r *Room) RemovePlayer(_player *player.Player) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if _, exists := r.spectators[_player.ID()]; exists {
+		delete(r.spectators, _player.ID())
+		delete(r.joinedAt, _player.ID())
+		return nil
+	}
+
 	_, exists := r.players[_player.ID()]
 	if !exists {
 		return fmt.Errorf("player not found in room")
 	}
 
 	delete(r.players, _player.ID())
+	delete(r.joinedAt, _player.ID())
+	delete(r.ready, _player.ID())
 	r.UpdateStatus(false)
 
 	return nil
 }
 
+func (r *Room) RenamePlayer(_player *player.Player, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isNameTaken(name, _player.ID()) {
+		return fmt.Errorf("name %s is already taken in this room", name)
+	}
+
+	_player.SetName(name)
+	return nil
+}
+
+func (r *Room) GetPlayerNames() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make(map[string]string, len(r.players))
+	for id, p := range r.players {
+		names[id] = p.GetName()
+	}
+
+	return names
+}
+
+func (r *Room) isNameTaken(name string, exceptID string) bool {
+	for _, members := range []map[string]*player.Player{r.players, r.spectators} {
+		for id, p := range members {
+			if id != exceptID && player.SameName(p.GetName(), name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (r *Room) GetPlayers() map[string]*player.Player {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -132,6 +393,35 @@ func (r *Room) GetPlayer(id string) (*player.Player, error) {
 	return _player, nil
 }
 
+func (r *Room) GetMember(id string) (*player.Player, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _player, exists := r.players[id]; exists {
+		return _player, nil
+	}
+	if _player, exists := r.spectators[id]; exists {
+		return _player, nil
+	}
+
+	return nil, fmt.Errorf("player not found in room")
+}
+
+func (r *Room) GetSpectators() map[string]*player.Player {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.spectators
+}
+
+func (r *Room) IsSpectator(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.spectators[id]
+	return exists
+}
+
 func (r *Room) GetPlayersCount() int {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -153,20 +443,122 @@ func (r *Room) GetOwnerID() string {
 	return r.ownerID
 }
 
-func (r *Room) Broadcast(msg message.Message, excludedPlayerId interface{}) {
+func (r *Room) AddChatMessage(msg message.ChatMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chat = append(r.chat, msg)
+	if len(r.chat) > ChatHistorySize {
+		r.chat = r.chat[len(r.chat)-ChatHistorySize:]
+	}
+}
+
+func (r *Room) GetChatHistory() []message.ChatMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := make([]message.ChatMessage, len(r.chat))
+	copy(history, r.chat)
+
+	return history
+}
+
+func (r *Room) LongestPresentPlayer() (*player.Player, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	var (
+		oldest   *player.Player
+		joinedAt time.Time
+	)
+	for id, p := range r.players {
+		if oldest == nil || r.joinedAt[id].Before(joinedAt) {
+			oldest = p
+			joinedAt = r.joinedAt[id]
+		}
+	}
+
+	return oldest, oldest != nil
+}
+
+func (r *Room) Broadcast(msg message.Message, excludedPlayerId interface{}) {
+	r.mu.Lock()
+	players := make([]*player.Player, 0, len(r.players)+len(r.spectators))
 	for _, p := range r.players {
 		if excludedPlayerId == nil || excludedPlayerId.(string) != p.ID() {
-			p.Send(msg)
+			players = append(players, p)
+		}
+	}
+	for _, p := range r.spectators {
+		if excludedPlayerId == nil || excludedPlayerId.(string) != p.ID() {
+			players = append(players, p)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, p := range players {
+		if err := p.Send(msg); err != nil && err != player.ErrClosed {
+			slog.Warn("Failed to send message", "room", r.id, "type", msg.Type, "player", p.ID(), "err", err)
+		}
+	}
+}
+
+func (r *Room) SetReady(playerID string, ready bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.players[playerID]; !exists {
+		return fmt.Errorf("only players can be ready")
+	}
+	if r.status == InGame {
+		return fmt.Errorf("there is a game going on in the room now")
+	}
+
+	r.ready[playerID] = ready
+	r.UpdateStatus(false)
+
+	return nil
+}
+
+func (r *Room) ResetReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ready = make(map[string]bool)
+	r.UpdateStatus(false)
+}
+
+func (r *Room) AllReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.allReady()
+}
+
+func (r *Room) allReady() bool {
+	for id := range r.players {
+		if !r.ready[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Room) readyPlayers() []string {
+	ids := make([]string, 0, len(r.ready))
+	for id, ready := range r.ready {
+		if _, exists := r.players[id]; exists && ready {
+			ids = append(ids, id)
 		}
 	}
+	sort.Strings(ids)
+
+	return ids
 }
 
 func (r *Room) UpdateStatus(gameStarted bool) {
 	if !gameStarted {
-		if len(r.players) >= r.settings.NeedPlayers {
+		if len(r.players) >= r.settings.NeedPlayers && r.allReady() {
 			r.status = Ready
 		} else {
 			r.status = Waiting
@@ -176,3 +568,244 @@ func (r *Room) UpdateStatus(gameStarted bool) {
 	}
 
 }
+
+func (r *Room) StartMatch(m *match.Match, gameData message.StartGameData, onOver func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		close(r.stop)
+	}
+
+	ids := m.Players()
+	active := make([]bool, len(ids))
+	for i, id := range ids {
+		active[i] = id != ""
+	}
+
+	r.match = m
+	r.inputs = rollback.NewInputQueue(active, r.settings.InputDelay)
+	r.history = make([]message.ConfirmedInputs, 0)
+	r.lastInput = make(map[string]uint64)
+	r.violations = make(map[string]int)
+	r.gameData = gameData
+	r.stop = make(chan struct{})
+
+	go r.runMatch(m, r.inputs, r.settings.RollbackWindow, time.UnixMilli(gameData.StartAt), r.stop, onOver)
+}
+
+type MatchRecord struct {
+	Match    *match.Match
+	GameData message.StartGameData
+	Inputs   []message.ConfirmedInputs
+}
+
+func (r *Room) StopMatch() (MatchRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+
+	record := MatchRecord{
+		Match:    r.match,
+		GameData: r.gameData,
+		Inputs:   r.history,
+	}
+	r.match = nil
+	r.inputs = nil
+	r.history = nil
+
+	return record, record.Match != nil
+}
+
+func (r *Room) SetPlayerConnected(playerID string, connected bool) {
+	r.mu.Lock()
+	m, inputs := r.match, r.inputs
+	delete(r.lastInput, playerID)
+	r.mu.Unlock()
+
+	if m == nil {
+		return
+	}
+
+	if slot, ok := m.Slot(playerID); ok {
+		inputs.SetActive(slot, connected)
+	}
+}
+
+func (r *Room) ResumeData() (message.ResumeGameData, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.match == nil {
+		return message.ResumeGameData{}, false
+	}
+
+	inputs := make([]message.ConfirmedInputs, len(r.history))
+	copy(inputs, r.history)
+
+	return message.ResumeGameData{
+		StartGame: r.gameData,
+		Inputs:    inputs,
+	}, true
+}
+
+func (r *Room) GetMatch() *match.Match {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.match
+}
+
+func (r *Room) AddInput(playerID string, input message.FrameInput) error {
+	r.mu.Lock()
+	m, inputs := r.match, r.inputs
+	r.mu.Unlock()
+
+	if m == nil {
+		return fmt.Errorf("there is no game going on in the room")
+	}
+
+	slot, ok := m.Slot(playerID)
+	if !ok {
+		return fmt.Errorf("player is not a fighter in this match")
+	}
+
+	return inputs.Add(slot, input.Frame, input.Control)
+}
+
+func (r *Room) IsFighter(playerID string) bool {
+	r.mu.Lock()
+	m := r.match
+	r.mu.Unlock()
+
+	if m == nil {
+		return false
+	}
+
+	_, ok := m.Slot(playerID)
+	return ok
+}
+
+func (r *Room) ValidateInput(playerID string, input message.FrameInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.match == nil {
+		return nil
+	}
+
+	var clockFrame uint64
+	if elapsed := time.Since(time.UnixMilli(r.gameData.StartAt)); elapsed > 0 {
+		clockFrame = uint64(elapsed / match.TickDuration)
+	}
+	maxFrame := clockFrame + uint64(r.settings.InputDelay+r.settings.RollbackWindow+InputClockTolerance)
+	if input.Frame > maxFrame {
+		return fmt.Errorf("input frame %d is ahead of the server clock (frame %d)", input.Frame, clockFrame)
+	}
+
+	if last, exists := r.lastInput[playerID]; exists && input.Frame <= last {
+		return fmt.Errorf("input frame %d was already sent", input.Frame)
+	}
+	r.lastInput[playerID] = input.Frame
+
+	return nil
+}
+
+func (r *Room) AddViolation(playerID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.violations == nil {
+		return 0
+	}
+
+	r.violations[playerID]++
+	return r.violations[playerID]
+}
+
+func (r *Room) runMatch(m *match.Match, inputs *rollback.InputQueue, rollbackWindow int, startTime time.Time, stop chan struct{}, onOver func()) {
+	select {
+	case <-stop:
+		return
+	case <-time.After(time.Until(startTime)):
+	}
+
+	ticker := time.NewTicker(match.TickDuration)
+	defer ticker.Stop()
+
+	roundEnded := false
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			targetFrame := uint64(time.Since(startTime) / match.TickDuration)
+
+			for inputs.Frame() < targetFrame && (inputs.Ready() || targetFrame-inputs.Frame() > uint64(rollbackWindow)) {
+				frame, controls := inputs.Confirm()
+				m.Advance(controls)
+
+				confirmed := message.ConfirmedInputs{
+					Frame:    frame,
+					Controls: controls,
+				}
+				r.recordInputs(m, confirmed)
+				r.Broadcast(message.Message{
+					Type: message.ConfirmedInputsMsg,
+					Data: confirmed,
+				}, nil)
+
+				if m.IsEnd() && !roundEnded {
+					r.Broadcast(message.Message{
+						Type: message.RoundEndMsg,
+						Data: roundResult(m),
+					}, nil)
+				}
+				roundEnded = m.IsEnd()
+
+				if m.Tick()%match.SnapshotInterval == 0 || m.IsOver() {
+					r.Broadcast(message.Message{
+						Type: message.GameStateMsg,
+						Data: m.Snapshot(),
+					}, nil)
+				}
+
+				if m.IsOver() {
+					onOver()
+					return
+				}
+			}
+		}
+	}
+}
+
+func roundResult(m *match.Match) message.RoundResult {
+	ids := m.Players()
+	wins := m.Wins()
+
+	result := message.RoundResult{
+		Round:         m.Round(),
+		WinnerID:      m.RoundWinner(),
+		Wins:          make(map[string]int, len(ids)),
+		MatchWinnerID: m.Winner(),
+	}
+	for i, id := range ids {
+		result.Wins[id] = wins[i]
+	}
+
+	return result
+}
+
+func (r *Room) recordInputs(m *match.Match, inputs message.ConfirmedInputs) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.match == m {
+		r.history = append(r.history, inputs)
+	}
+}
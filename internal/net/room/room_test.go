@@ -0,0 +1,112 @@
+package room
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/game/match"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+)
+
+func newMatchRoom(t *testing.T) (*Room, *player.Player, *player.Player) {
+	t.Helper()
+
+	chars, err := character.LoadCharacters(filepath.Join("..", "..", "..", "assets", "meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_room := NewRoom("ROOM01", RoomSettings{
+		MaxPlayers:     2,
+		NeedPlayers:    2,
+		MaxSpectators:  2,
+		InputDelay:     DefaultInputDelay,
+		RollbackWindow: DefaultRollbackWindow,
+	})
+	first := player.NewPlayer("first", player.DefaultOptions())
+	second := player.NewPlayer("second", player.DefaultOptions())
+	for _, p := range []*player.Player{first, second} {
+		if err := _room.AddPlayer(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := match.NewMatch(chars[character.WarriorName], map[string]int{first.ID(): 0, second.ID(): 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_room.StartMatch(m, message.StartGameData{StartAt: time.Now().Add(time.Hour).UnixMilli()}, func() {})
+	t.Cleanup(func() { _room.StopMatch() })
+
+	return _room, first, second
+}
+
+func TestValidateInputRejectsFramesAheadOfClock(t *testing.T) {
+	_room, first, second := newMatchRoom(t)
+	maxFrame := uint64(DefaultInputDelay + DefaultRollbackWindow + InputClockTolerance)
+
+	if err := _room.ValidateInput(first.ID(), message.FrameInput{Frame: maxFrame}); err != nil {
+		t.Fatalf("frame %d within the tolerance was rejected: %v", maxFrame, err)
+	}
+	if err := _room.ValidateInput(second.ID(), message.FrameInput{Frame: maxFrame + 1}); err == nil {
+		t.Fatalf("frame %d ahead of the server clock was accepted", maxFrame+1)
+	}
+}
+
+func TestValidateInputRejectsRepeatedFrames(t *testing.T) {
+	_room, first, _ := newMatchRoom(t)
+
+	for _, frame := range []uint64{0, 1} {
+		if err := _room.ValidateInput(first.ID(), message.FrameInput{Frame: frame}); err != nil {
+			t.Fatalf("frame %d was rejected: %v", frame, err)
+		}
+	}
+	for _, frame := range []uint64{1, 0} {
+		if err := _room.ValidateInput(first.ID(), message.FrameInput{Frame: frame}); err == nil {
+			t.Fatalf("repeated frame %d was accepted", frame)
+		}
+	}
+	if err := _room.ValidateInput(first.ID(), message.FrameInput{Frame: 2}); err != nil {
+		t.Fatalf("frame 2 was rejected: %v", err)
+	}
+
+	_room.SetPlayerConnected(first.ID(), true)
+	if err := _room.ValidateInput(first.ID(), message.FrameInput{Frame: 1}); err != nil {
+		t.Fatalf("frame resent after reconnecting was rejected: %v", err)
+	}
+}
+
+func TestIsFighter(t *testing.T) {
+	_room, first, _ := newMatchRoom(t)
+
+	spectator := player.NewPlayer("watcher", player.DefaultOptions())
+	if err := _room.AddSpectator(spectator); err != nil {
+		t.Fatal(err)
+	}
+
+	if !_room.IsFighter(first.ID()) {
+		t.Error("fighter is not reported as a fighter")
+	}
+	if _room.IsFighter(spectator.ID()) {
+		t.Error("spectator is reported as a fighter")
+	}
+}
+
+func TestSpectatorCannotJoinAsPlayer(t *testing.T) {
+	_room := NewRoom("ROOM02", RoomSettings{MaxPlayers: 2, NeedPlayers: 2, MaxSpectators: 2})
+	watcher := player.NewPlayer("watcher", player.DefaultOptions())
+
+	if err := _room.AddSpectator(watcher); err != nil {
+		t.Fatal(err)
+	}
+	if err := _room.AddPlayer(watcher); err == nil {
+		t.Fatal("spectator was added as a player")
+	}
+
+	info := _room.RoomInfo()
+	if len(info.Players) != 0 || len(info.Spectators) != 1 {
+		t.Fatalf("room has %d players and %d spectators, want 0 and 1", len(info.Players), len(info.Spectators))
+	}
+}
@@ -0,0 +1,141 @@
+package roommanager
+
+import (
+	"log/slog"
+	"sort"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+)
+
+const (
+	DefaultRoomsPageSize = 20
+	MaxRoomsPageSize     = 50
+)
+
+type subscription struct {
+	player *player.Player
+	filter message.RoomFilter
+}
+
+func (rm *RoomManager) ListRooms(filter message.RoomFilter, page int, pageSize int) message.RoomList {
+	if pageSize <= 0 {
+		pageSize = DefaultRoomsPageSize
+	}
+	if pageSize > MaxRoomsPageSize {
+		pageSize = MaxRoomsPageSize
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	rm.mu.Lock()
+	rooms := make([]*room.Room, 0, len(rm.rooms))
+	for _, _room := range rm.rooms {
+		rooms = append(rooms, _room)
+	}
+	rm.mu.Unlock()
+
+	sort.Slice(rooms, func(i, j int) bool {
+		if rooms[i].GetCreatedAt().Equal(rooms[j].GetCreatedAt()) {
+			return rooms[i].ID() < rooms[j].ID()
+		}
+		return rooms[i].GetCreatedAt().Before(rooms[j].GetCreatedAt())
+	})
+
+	matched := make([]message.RoomInfo, 0)
+	for _, _room := range rooms {
+		if info := _room.RoomInfo(); matchesFilter(info, filter) {
+			matched = append(matched, info)
+		}
+	}
+
+	start := page * pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return message.RoomList{
+		Rooms:    matched[start:end],
+		Page:     page,
+		PageSize: pageSize,
+		Total:    len(matched),
+	}
+}
+
+func (rm *RoomManager) Subscribe(_player *player.Player, filter message.RoomFilter) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.subscribers[_player.ID()] = &subscription{
+		player: _player,
+		filter: filter,
+	}
+}
+
+func (rm *RoomManager) Unsubscribe(playerID string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	delete(rm.subscribers, playerID)
+}
+
+func (rm *RoomManager) NotifyRoomChanged(roomCode string) {
+	_room, err := rm.GetRoom(roomCode)
+	if err != nil {
+		return
+	}
+
+	rm.notifySubscribers(_room.RoomInfo(), false)
+}
+
+func (rm *RoomManager) notifySubscribers(info message.RoomInfo, removed bool) {
+	if !info.Public {
+		return
+	}
+
+	rm.mu.Lock()
+	subscribers := make([]*subscription, 0, len(rm.subscribers))
+	for _, sub := range rm.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	rm.mu.Unlock()
+
+	for _, sub := range subscribers {
+		update := message.RoomListUpdate{
+			Room:    info,
+			Removed: removed || !matchesFilter(info, sub.filter),
+		}
+		if update.Removed {
+			update.Room = message.RoomInfo{ID: info.ID}
+		}
+
+		err := sub.player.Send(message.Message{
+			Type: message.RoomListUpdateMsg,
+			Data: update,
+		})
+		if err == player.ErrClosed {
+			rm.Unsubscribe(sub.player.ID())
+		} else if err != nil {
+			slog.Warn("Failed to notify player of room list", "player", sub.player.ID(), "err", err)
+		}
+	}
+}
+
+func matchesFilter(info message.RoomInfo, filter message.RoomFilter) bool {
+	if !info.Public {
+		return false
+	}
+	if filter.Status != "" && filter.Status != info.Status {
+		return false
+	}
+	if filter.MinFreeSlots > 0 && info.MaxPlayers-info.PlayersCount < filter.MinFreeSlots {
+		return false
+	}
+
+	return true
+}
@@ -1,6 +1,7 @@
 package roommanager
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"webgl-app/internal/net/player"
@@ -8,14 +9,34 @@ import (
 	"webgl-app/internal/utils"
 )
 
+var ErrRoomLimit = errors.New("room limit reached")
+
+type Limits struct {
+	MaxRooms         int
+	MaxRoomsPerOwner int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxRooms:         1000,
+		MaxRoomsPerOwner: 3,
+	}
+}
+
 type RoomManager struct {
-	rooms map[string]*room.Room
-	mu    sync.Mutex
+	rooms       map[string]*room.Room
+	creators    map[string]string
+	subscribers map[string]*subscription
+	limits      Limits
+	mu          sync.Mutex
 }
 
-func NewRoomManager() *RoomManager {
+func NewRoomManager(limits Limits) *RoomManager {
 	return &RoomManager{
-		rooms: make(map[string]*room.Room),
+		rooms:       make(map[string]*room.Room),
+		creators:    make(map[string]string),
+		subscribers: make(map[string]*subscription),
+		limits:      limits,
 	}
 }
 
@@ -23,6 +44,39 @@ func (rm *RoomManager) CreateRoom(ownerID string, settings room.RoomSettings) (s
 	if settings.MaxPlayers <= 0 {
 		return "", fmt.Errorf("invalid max players count")
 	}
+	if settings.InputDelay == 0 {
+		settings.InputDelay = room.DefaultInputDelay
+	}
+	if settings.InputDelay < 0 || settings.InputDelay > room.MaxInputDelay {
+		return "", fmt.Errorf("invalid input delay")
+	}
+	if settings.RollbackWindow == 0 {
+		settings.RollbackWindow = room.DefaultRollbackWindow
+	}
+	if settings.RollbackWindow < 0 || settings.RollbackWindow > room.MaxRollbackWindow {
+		return "", fmt.Errorf("invalid rollback window")
+	}
+	if settings.MaxSpectators == 0 {
+		settings.MaxSpectators = room.DefaultMaxSpectators
+	}
+	if settings.MaxSpectators < 0 || settings.MaxSpectators > room.MaxSpectatorsLimit {
+		return "", fmt.Errorf("invalid max spectators count")
+	}
+	if settings.OwnerLeave == "" {
+		settings.OwnerLeave = room.CloseRoom
+	}
+	if settings.OwnerLeave != room.CloseRoom && settings.OwnerLeave != room.MigrateOwner {
+		return "", fmt.Errorf("invalid owner leave policy")
+	}
+	if settings.BestOf == 0 {
+		settings.BestOf = room.DefaultBestOf
+	}
+	if settings.BestOf < 0 || settings.BestOf > room.MaxBestOf || settings.BestOf%2 == 0 {
+		return "", fmt.Errorf("best of must be an odd number of rounds up to %d", room.MaxBestOf)
+	}
+	if len(settings.Password) > room.MaxPasswordLength {
+		return "", fmt.Errorf("password is longer than %d characters", room.MaxPasswordLength)
+	}
 
 	roomCode, err := rm.generateRoomCode(6)
 	if err != nil {
@@ -33,30 +87,47 @@ func (rm *RoomManager) CreateRoom(ownerID string, settings room.RoomSettings) (s
 	_room.SetOwnerID(ownerID)
 
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
+	if err := rm.checkLimits(ownerID); err != nil {
+		rm.mu.Unlock()
+		return "", err
+	}
 	rm.rooms[roomCode] = _room
+	rm.creators[roomCode] = ownerID
+	rm.mu.Unlock()
+
+	rm.NotifyRoomChanged(roomCode)
 
 	return roomCode, nil
 }
 
 func (rm *RoomManager) DeleteRoom(roomCode string) error {
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	if _, exists := rm.rooms[roomCode]; !exists {
+	_room, exists := rm.rooms[roomCode]
+	if !exists {
+		rm.mu.Unlock()
 		return fmt.Errorf("room not found")
 	}
 
-	for _, p := range rm.rooms[roomCode].GetPlayers() {
+	for _, p := range _room.GetPlayers() {
+		p.SetRoomID("")
+	}
+	for _, p := range _room.GetSpectators() {
 		p.SetRoomID("")
 	}
 	delete(rm.rooms, roomCode)
+	delete(rm.creators, roomCode)
+	rm.mu.Unlock()
+
+	rm.notifySubscribers(_room.RoomInfo(), true)
 
 	return nil
 }
 
-func (rm *RoomManager) JoinRoom(_player *player.Player, roomCode string) error {
+func (rm *RoomManager) JoinRoom(_player *player.Player, roomCode string, password string) error {
+	if _player.GetRoomID() != "" {
+		return fmt.Errorf("leave the current room first")
+	}
+
 	rm.mu.Lock()
 	_room, exists := rm.rooms[roomCode]
 	rm.mu.Unlock()
@@ -64,11 +135,40 @@ func (rm *RoomManager) JoinRoom(_player *player.Player, roomCode string) error {
 	if !exists {
 		return fmt.Errorf("room  not found")
 	}
+	if !_room.CheckPassword(password) {
+		return fmt.Errorf("wrong room password")
+	}
 
 	if err := _room.AddPlayer(_player); err != nil {
 		return err
 	}
 	_player.SetRoomID(roomCode)
+	rm.NotifyRoomChanged(roomCode)
+
+	return nil
+}
+
+func (rm *RoomManager) SpectateRoom(_player *player.Player, roomCode string, password string) error {
+	if _player.GetRoomID() != "" {
+		return fmt.Errorf("leave the current room first")
+	}
+
+	rm.mu.Lock()
+	_room, exists := rm.rooms[roomCode]
+	rm.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("room not found")
+	}
+	if !_room.CheckPassword(password) {
+		return fmt.Errorf("wrong room password")
+	}
+
+	if err := _room.AddSpectator(_player); err != nil {
+		return err
+	}
+	_player.SetRoomID(roomCode)
+	rm.NotifyRoomChanged(roomCode)
 
 	return nil
 }
@@ -86,6 +186,7 @@ func (rm *RoomManager) KickFromRoom(_player *player.Player, roomCode string) err
 		return err
 	}
 	_player.SetRoomID("")
+	rm.NotifyRoomChanged(roomCode)
 
 	return nil
 }
@@ -102,6 +203,26 @@ func (rm *RoomManager) GetRoom(roomCode string) (*room.Room, error) {
 	return _room, nil
 }
 
+func (rm *RoomManager) checkLimits(ownerID string) error {
+	if rm.limits.MaxRooms > 0 && len(rm.rooms) >= rm.limits.MaxRooms {
+		return fmt.Errorf("%w: the server is full, try again later", ErrRoomLimit)
+	}
+
+	if rm.limits.MaxRoomsPerOwner > 0 {
+		created := 0
+		for _, creatorID := range rm.creators {
+			if creatorID == ownerID {
+				created++
+			}
+		}
+		if created >= rm.limits.MaxRoomsPerOwner {
+			return fmt.Errorf("%w: you cannot have more than %d open rooms", ErrRoomLimit, rm.limits.MaxRoomsPerOwner)
+		}
+	}
+
+	return nil
+}
+
 func (rm *RoomManager) generateRoomCode(lenght int) (string, error) {
 	const maxAttempts = 100
 	for i := 0; i < maxAttempts; i++ {
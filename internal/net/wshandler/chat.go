@@ -0,0 +1,61 @@
+package wshandler
+
+import (
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+)
+
+func (ws *WebSocket) handleChatMessage(_player *player.Player, msg message.Message) {
+	roomCode := _player.GetRoomID()
+	if roomCode == "" {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "player is not in any room",
+		})
+		return
+	}
+
+	_room, err := ws.rm.GetRoom(roomCode)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	text, _ := msg.Data.(string)
+	text, err = ws.chat.Moderate(_player.ID(), text, _room.Mutes())
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ChatMessageMsg,
+			Data: message.ChatMessage{
+				Text: err.Error(),
+				Time: time.Now().UnixMilli(),
+			},
+		})
+		return
+	}
+
+	chatMessage := message.ChatMessage{
+		PlayerID: _player.ID(),
+		Name:     _player.GetName(),
+		Text:     text,
+		Time:     time.Now().UnixMilli(),
+	}
+
+	_room.AddChatMessage(chatMessage)
+	_room.Broadcast(message.Message{
+		Type: message.ChatMessageMsg,
+		Data: chatMessage,
+	}, nil)
+}
+
+func (ws *WebSocket) sendChatHistory(_player *player.Player, _room *room.Room) {
+	_player.Send(message.Message{
+		Type: message.ChatHistoryMsg,
+		Data: _room.GetChatHistory(),
+	})
+}
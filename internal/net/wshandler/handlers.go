@@ -1,10 +1,18 @@
 package wshandler
 
 import (
+	"errors"
+	"math/rand"
+	"time"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/game/match"
 	"webgl-app/internal/net/message"
 	"webgl-app/internal/net/player"
 	"webgl-app/internal/net/room"
+	"webgl-app/internal/net/roommanager"
 	"webgl-app/internal/utils"
+
+	"github.com/google/uuid"
 )
 
 func (ws *WebSocket) handleMessage(_player *player.Player, msg message.Message) {
@@ -13,6 +21,8 @@ func (ws *WebSocket) handleMessage(_player *player.Player, msg message.Message)
 		ws.handleCreateRoom(_player, msg)
 	case message.JoinRoomMsg:
 		ws.handleJoinRoom(_player, msg)
+	case message.SpectateRoomMsg:
+		ws.handleSpectateRoom(_player, msg)
 	case message.LeaveRoomMsg:
 		ws.handleLeaveRoom(_player)
 	case message.StartGameMsg:
@@ -23,8 +33,34 @@ func (ws *WebSocket) handleMessage(_player *player.Player, msg message.Message)
 		ws.handleUpdateRoomInfo(_player)
 	case message.UpdatePlayerInfoMsg:
 		ws.handleUpdatePlayerInfo(_player)
-	case message.GameStateMsg:
-		ws.handlerGameState(_player, msg)
+	case message.PlayerInputMsg:
+		ws.handlePlayerInput(_player, msg)
+	case message.ListRoomsMsg:
+		ws.handleListRooms(_player, msg)
+	case message.UnsubscribeRoomsMsg:
+		ws.rm.Unsubscribe(_player.ID())
+	case message.QueueJoinMsg:
+		ws.handleQueueJoin(_player)
+	case message.QueueLeaveMsg:
+		ws.handleQueueLeave(_player)
+	case message.ChatMessageMsg:
+		ws.handleChatMessage(_player, msg)
+	case message.SetProfileMsg:
+		ws.handleSetProfile(_player, msg)
+	case message.KickPlayerMsg:
+		ws.handleKickPlayer(_player, msg)
+	case message.MutePlayerMsg:
+		ws.handleMutePlayer(_player, msg)
+	case message.LockRoomMsg:
+		ws.handleLockRoom(_player, msg)
+	case message.SetRoomPasswordMsg:
+		ws.handleSetRoomPassword(_player, msg)
+	case message.TransferOwnerMsg:
+		ws.handleTransferOwner(_player, msg)
+	case message.SetReadyMsg:
+		ws.handleSetReady(_player, msg)
+	case message.TimeSyncMsg:
+		ws.handleTimeSync(_player, msg)
 	default:
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
@@ -37,7 +73,19 @@ func (ws *WebSocket) handleCreateRoom(_player *player.Player, msg message.Messag
 	var settings room.RoomSettings
 	utils.ParseInterfaceToJSON(msg.Data, &settings)
 
+	if _player.GetRoomID() != "" {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "leave the current room first",
+		})
+		return
+	}
+
 	roomCode, err := ws.rm.CreateRoom(_player.ID(), settings)
+	if errors.Is(err, roommanager.ErrRoomLimit) {
+		sendError(_player, message.RoomLimitError, err.Error(), 0)
+		return
+	}
 	if err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
@@ -46,7 +94,7 @@ func (ws *WebSocket) handleCreateRoom(_player *player.Player, msg message.Messag
 		return
 	}
 
-	err = ws.rm.JoinRoom(_player, roomCode)
+	err = ws.rm.JoinRoom(_player, roomCode, settings.Password)
 	if err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
@@ -55,6 +103,7 @@ func (ws *WebSocket) handleCreateRoom(_player *player.Player, msg message.Messag
 		ws.rm.DeleteRoom(roomCode)
 		return
 	}
+	ws.leaveQueue(_player)
 
 	_player.Send(message.Message{
 		Type: message.CreateRoomMsg,
@@ -63,9 +112,10 @@ func (ws *WebSocket) handleCreateRoom(_player *player.Player, msg message.Messag
 }
 
 func (ws *WebSocket) handleJoinRoom(_player *player.Player, msg message.Message) {
-	roomCode := msg.Data.(string)
+	request := parseJoinRoomRequest(msg.Data)
+	roomCode := request.RoomID
 
-	err := ws.rm.JoinRoom(_player, roomCode)
+	err := ws.rm.JoinRoom(_player, roomCode, request.Password)
 	if err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
@@ -73,12 +123,48 @@ func (ws *WebSocket) handleJoinRoom(_player *player.Player, msg message.Message)
 		})
 		return
 	}
+	ws.leaveQueue(_player)
 
 	_room, _ := ws.rm.GetRoom(roomCode)
 	_player.Send(message.Message{
 		Type: message.JoinRoomMsg,
 		Data: nil,
 	})
+	ws.sendChatHistory(_player, _room)
+
+	_room.Broadcast(message.Message{
+		Type: message.PlayerJoinMsg,
+		Data: _player.GetName(),
+	}, _player.ID())
+}
+
+func (ws *WebSocket) handleSpectateRoom(_player *player.Player, msg message.Message) {
+	request := parseJoinRoomRequest(msg.Data)
+	roomCode := request.RoomID
+
+	err := ws.rm.SpectateRoom(_player, roomCode, request.Password)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+	ws.leaveQueue(_player)
+
+	_room, _ := ws.rm.GetRoom(roomCode)
+	_player.Send(message.Message{
+		Type: message.SpectateRoomMsg,
+		Data: nil,
+	})
+	ws.sendChatHistory(_player, _room)
+
+	if resumeData, ok := _room.ResumeData(); ok {
+		_player.Send(message.Message{
+			Type: message.ResumeGameMsg,
+			Data: resumeData,
+		})
+	}
 
 	_room.Broadcast(message.Message{
 		Type: message.PlayerJoinMsg,
@@ -105,6 +191,10 @@ func (ws *WebSocket) handleLeaveRoom(_player *player.Player) {
 		return
 	}
 
+	if _room.IsFighter(_player.ID()) {
+		ws.endGame(_room, _player.ID())
+	}
+
 	if err := ws.rm.KickFromRoom(_player, roomCode); err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
@@ -117,18 +207,41 @@ func (ws *WebSocket) handleLeaveRoom(_player *player.Player) {
 		Data: nil,
 	})
 
-	if _room.GetOwnerID() == _player.ID() {
+	if _room.GetOwnerID() == _player.ID() && !ws.migrateOwner(_room, _player) {
 		ws.rm.DeleteRoom(roomCode)
 		_room.Broadcast(message.Message{
 			Type: message.RoomClosedMsg,
 			Data: "the owner has closed the room",
 		}, _player.ID())
-	} else {
-		_room.Broadcast(message.Message{
-			Type: message.PlayerLeftMsg,
-			Data: _player.ID(),
-		}, nil)
+		return
+	}
+
+	_room.Broadcast(message.Message{
+		Type: message.PlayerLeftMsg,
+		Data: _player.ID(),
+	}, nil)
+}
+
+func (ws *WebSocket) migrateOwner(_room *room.Room, previousOwner *player.Player) bool {
+	if _room.GetSettings().OwnerLeave != room.MigrateOwner {
+		return false
+	}
+
+	owner, ok := _room.LongestPresentPlayer()
+	if !ok {
+		return false
 	}
+
+	_room.SetOwnerID(owner.ID())
+	ws.rm.NotifyRoomChanged(_room.ID())
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:       message.OwnerChangedEvent,
+		ActorID:    previousOwner.ID(),
+		PlayerID:   owner.ID(),
+		PlayerName: owner.GetName(),
+	})
+
+	return true
 }
 
 func (ws *WebSocket) handleStartGame(_player *player.Player) {
@@ -150,6 +263,39 @@ func (ws *WebSocket) handleStartGame(_player *player.Player) {
 		return
 	}
 
+	if _room.IsSpectator(_player.ID()) {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "spectators cannot start the game",
+		})
+		return
+	}
+
+	if _room.GetOwnerID() != _player.ID() {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "only the room owner can start the game",
+		})
+		return
+	}
+
+	if _room.GetStatus() != room.Ready {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "not all players are ready",
+		})
+		return
+	}
+
+	if err := ws.startGame(_room, _player.ID()); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+	}
+}
+
+func (ws *WebSocket) startGame(_room *room.Room, starterID string) error {
 	ids := make([]string, 0)
 	for id := range _room.GetPlayers() {
 		ids = append(ids, id)
@@ -157,20 +303,48 @@ func (ws *WebSocket) handleStartGame(_player *player.Player) {
 
 	fightersPositions := make(map[string]int, 2)
 	for _, id := range ids {
-		if _player.ID() == id {
+		if starterID == id {
 			fightersPositions[id] = 0
 		} else {
 			fightersPositions[id] = 1
 		}
 	}
 
+	gameMatch, err := match.NewMatch(ws.characters[character.WarriorName], fightersPositions)
+	if err != nil {
+		return err
+	}
+	gameMatch.SetStartCooldown(ws.settings.StartCountdown)
+	gameMatch.SetBestOf(_room.GetSettings().BestOf)
+
+	now := time.Now()
+	gameData := message.StartGameData{
+		MatchID:           uuid.New().String(),
+		Level:             match.Levels[rand.Intn(len(match.Levels))],
+		Seed:              rand.Int63(),
+		FightersPositions: fightersPositions,
+		PlayerNames:       _room.GetPlayerNames(),
+		InputDelay:        _room.GetSettings().InputDelay,
+		RollbackWindow:    _room.GetSettings().RollbackWindow,
+		BestOf:            _room.GetSettings().BestOf,
+		ServerTime:        now.UnixMilli(),
+		StartAt:           now.Add(ws.settings.StartLeadTime).UnixMilli(),
+		Countdown:         ws.settings.StartCountdown.Milliseconds(),
+	}
+
 	_room.UpdateStatus(true)
+	ws.rm.NotifyRoomChanged(_room.ID())
+	_room.StartMatch(gameMatch, gameData, func() {
+		if _room.GetMatch() == gameMatch {
+			ws.endGame(_room, "")
+		}
+	})
 	_room.Broadcast(message.Message{
 		Type: message.StartGameMsg,
-		Data: message.StartGameData{
-			FightersPositions: fightersPositions,
-		},
+		Data: gameData,
 	}, nil)
+
+	return nil
 }
 
 func (ws *WebSocket) handleEndGame(_player *player.Player) {
@@ -192,7 +366,29 @@ func (ws *WebSocket) handleEndGame(_player *player.Player) {
 		return
 	}
 
-	_room.UpdateStatus(false)
+	if !_room.IsFighter(_player.ID()) {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "only fighters can end the game",
+		})
+		return
+	}
+
+	ws.endGame(_room, _player.ID())
+}
+
+func (ws *WebSocket) endGame(_room *room.Room, forfeiterID string) {
+	record, ok := _room.StopMatch()
+	if !ok {
+		return
+	}
+
+	ws.recordResult(_room, record, forfeiterID)
+	if _room.GetSettings().Record {
+		ws.saveReplay(_room, record)
+	}
+	_room.ResetReady()
+	ws.rm.NotifyRoomChanged(_room.ID())
 	_room.Broadcast(message.Message{
 		Type: message.EndGameMsg,
 		Data: nil,
@@ -231,18 +427,45 @@ func (ws *WebSocket) handleUpdatePlayerInfo(_player *player.Player) {
 	})
 }
 
-func (ws *WebSocket) handlerGameState(_player *player.Player, msg message.Message) {
+func (ws *WebSocket) handlePlayerInput(_player *player.Player, msg message.Message) {
 	roomCode := _player.GetRoomID()
 	if roomCode == "" {
+		return
+	}
+
+	_room, err := ws.rm.GetRoom(roomCode)
+	if err != nil {
+		sendError(_player, message.NoMatchError, err.Error(), 0)
+		return
+	}
+
+	var input message.FrameInput
+	if err := utils.ParseInterfaceToJSON(msg.Data, &input); err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
-			Data: "Player is not in any room",
+			Data: err.Error(),
 		})
 		return
 	}
 
-	_room, err := ws.rm.GetRoom(roomCode)
-	if err != nil {
+	if !_room.IsFighter(_player.ID()) {
+		if _room.GetMatch() != nil {
+			sendError(_player, message.NoMatchError, "only fighters can send input", 0)
+		}
+		return
+	}
+
+	if err := _room.ValidateInput(_player.ID(), input); err != nil {
+		ws.reportViolation(_room, _player, err)
+		return
+	}
+
+	_room.AddInput(_player.ID(), input)
+}
+
+func (ws *WebSocket) handleListRooms(_player *player.Player, msg message.Message) {
+	var request message.ListRoomsRequest
+	if err := utils.ParseInterfaceToJSON(msg.Data, &request); err != nil {
 		_player.Send(message.Message{
 			Type: message.ErrorMsg,
 			Data: err.Error(),
@@ -250,5 +473,14 @@ func (ws *WebSocket) handlerGameState(_player *player.Player, msg message.Messag
 		return
 	}
 
-	_room.Broadcast(msg, _player.ID())
+	if request.Subscribe {
+		ws.rm.Subscribe(_player, request.Filter)
+	} else {
+		ws.rm.Unsubscribe(_player.ID())
+	}
+
+	_player.Send(message.Message{
+		Type: message.ListRoomsMsg,
+		Data: ws.rm.ListRooms(request.Filter, request.Page, request.PageSize),
+	})
 }
@@ -0,0 +1,118 @@
+package wshandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"webgl-app/internal/net/codec"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/protocol"
+	"webgl-app/internal/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+const handshakeTimeout = 10 * time.Second
+
+type handshakeResult struct {
+	player  *player.Player
+	token   string
+	resumed bool
+}
+
+func (ws *WebSocket) handshake(conn *websocket.Conn, r *http.Request) (handshakeResult, error) {
+	conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	msgType, rdmsg, err := conn.ReadMessage()
+	if err != nil {
+		return handshakeResult{}, err
+	}
+
+	msg, err := decoderFor(msgType).Decode(rdmsg)
+	if err != nil {
+		return handshakeResult{}, err
+	}
+	if msg.Type != message.HelloMsg {
+		return handshakeResult{}, rejectHandshake(conn, "handshake required, please reload the page")
+	}
+
+	var hello message.Hello
+	if err := utils.ParseInterfaceToJSON(msg.Data, &hello); err != nil {
+		return handshakeResult{}, rejectHandshake(conn, "invalid hello message, please reload the page")
+	}
+
+	compressed := strings.Contains(r.Header.Get("Sec-WebSocket-Extensions"), protocol.CompressionDeflate)
+	welcome, err := protocol.Negotiate(hello, compressed)
+	if err != nil {
+		return handshakeResult{}, rejectHandshake(conn, err.Error())
+	}
+
+	c, err := codec.ByName(welcome.Codec)
+	if err != nil {
+		return handshakeResult{}, rejectHandshake(conn, err.Error())
+	}
+
+	result := handshakeResult{
+		token: hello.SessionToken,
+	}
+	if result.token != "" {
+		result.player, result.resumed = ws.sessions.resume(result.token)
+		if !result.resumed {
+			result.player = ws.restoreIdentity(result.token)
+		}
+	}
+	if result.player == nil {
+		result.player = player.NewPlayer("", ws.settings.Player)
+		result.token, err = ws.sessions.create(result.player)
+		if err != nil {
+			return handshakeResult{}, rejectHandshake(conn, "failed to create session")
+		}
+	}
+	ws.saveIdentity(result.token, result.player)
+
+	welcome.PlayerID = result.player.ID()
+	welcome.SessionToken = result.token
+	welcome.Resumed = result.resumed
+
+	err = result.player.Attach(conn, c, message.Message{
+		Type: message.WelcomeMsg,
+		Data: welcome,
+	})
+	if err != nil {
+		return handshakeResult{}, err
+	}
+
+	return result, nil
+}
+
+func rejectHandshake(conn *websocket.Conn, reason string) error {
+	writeMessage(conn, codec.JSON{}, message.Message{
+		Type: message.ErrorMsg,
+		Data: reason,
+	})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ""))
+
+	return fmt.Errorf("handshake rejected: %s", reason)
+}
+
+func writeMessage(conn *websocket.Conn, c codec.Codec, msg message.Message) error {
+	data, err := c.Encode(msg)
+	if err != nil {
+		return err
+	}
+
+	if c.IsBinary() {
+		return conn.WriteMessage(websocket.BinaryMessage, data)
+	}
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func decoderFor(msgType int) codec.Codec {
+	if msgType == websocket.BinaryMessage {
+		return codec.Binary{}
+	}
+	return codec.JSON{}
+}
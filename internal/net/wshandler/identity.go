@@ -0,0 +1,71 @@
+package wshandler
+
+import (
+	"log/slog"
+	"time"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/storage"
+)
+
+func (ws *WebSocket) restoreIdentity(token string) *player.Player {
+	session, err := ws.store.GetSession(token)
+	if err != nil {
+		return nil
+	}
+
+	record, err := ws.store.GetPlayer(session.PlayerID)
+	if err != nil {
+		slog.Warn("Failed to restore player", "player", session.PlayerID, "err", err)
+		return nil
+	}
+
+	_player := player.RestorePlayer(record.ID, record.Name, ws.settings.Player)
+	if r, err := ws.store.GetRating(record.ID); err == nil {
+		_player.SetRating(r)
+	}
+	ws.sessions.add(token, _player)
+
+	return _player
+}
+
+func (ws *WebSocket) saveProfile(_player *player.Player) {
+	record, err := ws.store.GetPlayer(_player.ID())
+	if err != nil {
+		record = storage.PlayerRecord{
+			ID:        _player.ID(),
+			CreatedAt: time.Now(),
+		}
+	}
+	record.Name = _player.GetName()
+
+	if err := ws.store.SavePlayer(record); err != nil {
+		slog.Error("Failed to save player profile", "player", _player.ID(), "err", err)
+	}
+}
+
+func (ws *WebSocket) saveIdentity(token string, _player *player.Player) {
+	now := time.Now()
+
+	record, err := ws.store.GetPlayer(_player.ID())
+	if err != nil {
+		record = storage.PlayerRecord{
+			ID:        _player.ID(),
+			CreatedAt: now,
+		}
+	}
+	record.Name = _player.GetName()
+	record.LastSeen = now
+
+	if err := ws.store.SavePlayer(record); err != nil {
+		slog.Error("Failed to save player", "player", _player.ID(), "err", err)
+	}
+
+	err = ws.store.SaveSession(storage.SessionRecord{
+		Token:     token,
+		PlayerID:  _player.ID(),
+		ExpiresAt: now.Add(ws.settings.SessionLifetime),
+	})
+	if err != nil {
+		slog.Error("Failed to save session", "player", _player.ID(), "err", err)
+	}
+}
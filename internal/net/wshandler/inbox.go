@@ -0,0 +1,61 @@
+package wshandler
+
+import (
+	"sync"
+	"webgl-app/internal/net/message"
+)
+
+const inboxSize = 64
+
+type inboxItem struct {
+	msg    message.Message
+	action func()
+}
+
+type inboxes struct {
+	entries map[string]chan inboxItem
+	mu      sync.Mutex
+}
+
+func newInboxes() *inboxes {
+	return &inboxes{
+		entries: make(map[string]chan inboxItem),
+	}
+}
+
+func (i *inboxes) open(playerID string) chan inboxItem {
+	inbox := make(chan inboxItem, inboxSize)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.entries[playerID] = inbox
+	return inbox
+}
+
+func (i *inboxes) close(playerID string, inbox chan inboxItem) {
+	i.mu.Lock()
+	if i.entries[playerID] == inbox {
+		delete(i.entries, playerID)
+	}
+	i.mu.Unlock()
+
+	close(inbox)
+}
+
+func (i *inboxes) post(playerID string, action func()) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	inbox, exists := i.entries[playerID]
+	if !exists {
+		return false
+	}
+
+	select {
+	case inbox <- inboxItem{action: action}:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package wshandler
+
+import (
+	"testing"
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/storage"
+)
+
+func TestProcessMessagesKeepsOrderAndDrainsOnClose(t *testing.T) {
+	settings := DefaultSettings()
+	ws := NewWebSocket(nil, storage.NewMemoryStorage(), nil, settings)
+	_player := player.NewPlayer("", settings.Player)
+
+	messages := []message.Message{
+		{Type: message.ChatMessageMsg, Data: "before the room exists"},
+		{Type: message.SetProfileMsg, Data: message.Profile{Name: "alpha"}},
+		{Type: message.CreateRoomMsg, Data: room.RoomSettings{MaxPlayers: 2, NeedPlayers: 2}},
+		{Type: message.ChatMessageMsg, Data: "one"},
+		{Type: message.SetProfileMsg, Data: message.Profile{Name: "beta"}},
+		{Type: message.ChatMessageMsg, Data: "two"},
+		{Type: message.LockRoomMsg, Data: true},
+		{Type: message.ChatMessageMsg, Data: "three"},
+	}
+
+	inbox := make(chan inboxItem, len(messages))
+	for _, msg := range messages {
+		inbox <- inboxItem{msg: msg}
+	}
+	close(inbox)
+
+	processed := make(chan struct{})
+	go ws.processMessages(_player, inbox, processed)
+
+	select {
+	case <-processed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("processMessages did not return after the inbox was closed")
+	}
+
+	_room, err := ws.rm.GetRoom(_player.GetRoomID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !_room.RoomInfo().Locked {
+		t.Error("lock_room queued after create_room was not applied")
+	}
+
+	want := []message.ChatMessage{
+		{Name: "alpha", Text: "one"},
+		{Name: "beta", Text: "two"},
+		{Name: "beta", Text: "three"},
+	}
+	history := _room.GetChatHistory()
+	if len(history) != len(want) {
+		t.Fatalf("chat history has %d messages, want %d: %+v", len(history), len(want), history)
+	}
+	for i, msg := range history {
+		if msg.Name != want[i].Name || msg.Text != want[i].Text {
+			t.Errorf("message %d is %s: %q, want %s: %q", i, msg.Name, msg.Text, want[i].Name, want[i].Text)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package wshandler
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/ratelimit"
+
+	"github.com/gorilla/websocket"
+)
+
+func (ws *WebSocket) rateLimited(conn *websocket.Conn, _player *player.Player, limiter *ratelimit.Limiter, msgType message.MessageType, retryAfter time.Duration) bool {
+	count := limiter.AddViolation()
+	sendError(_player, message.RateLimitedError, fmt.Sprintf("too many %s messages, slow down", msgType), retryAfter)
+
+	if ws.settings.Limits.MaxViolations <= 0 || count < ws.settings.Limits.MaxViolations {
+		return true
+	}
+
+	slog.Warn("Player disconnected after rate limit violations", "player", _player.ID(), "violations", count)
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded"), time.Now().Add(time.Second))
+
+	return false
+}
+
+func sendError(_player *player.Player, code message.ErrorCode, text string, retryAfter time.Duration) {
+	_player.Send(message.Message{
+		Type: message.ErrorMsg,
+		Data: message.Error{
+			Code:       code,
+			Message:    text,
+			RetryAfter: retryAfter.Milliseconds(),
+		},
+	})
+}
+
+func originChecker(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		for _, o := range allowed {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
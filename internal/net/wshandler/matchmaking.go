@@ -0,0 +1,140 @@
+package wshandler
+
+import (
+	"log/slog"
+	"webgl-app/internal/net/matchmaker"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+)
+
+func (ws *WebSocket) handleQueueJoin(_player *player.Player) {
+	if _player.GetRoomID() != "" {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "leave the room before joining the queue",
+		})
+		return
+	}
+
+	if err := ws.mm.Enqueue(_player, _player.GetRating().Rating); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	ws.sendQueueStatus(_player, ws.mm.Status(_player.ID()))
+}
+
+func (ws *WebSocket) handleQueueLeave(_player *player.Player) {
+	ws.mm.Dequeue(_player.ID())
+	ws.sendQueueStatus(_player, matchmaker.Status{})
+}
+
+func (ws *WebSocket) sendQueueStatus(_player *player.Player, status matchmaker.Status) {
+	_player.Send(message.Message{
+		Type: message.QueueStatusMsg,
+		Data: message.QueueStatus{
+			InQueue:              status.InQueue,
+			Matched:              status.Matched,
+			Position:             status.Position,
+			QueueSize:            status.QueueSize,
+			WaitedSeconds:        int(status.Waited.Seconds()),
+			EstimatedWaitSeconds: int(status.EstimatedWait.Seconds()),
+		},
+	})
+}
+
+func (ws *WebSocket) leaveQueue(_player *player.Player) {
+	if ws.mm.Dequeue(_player.ID()) {
+		ws.sendQueueStatus(_player, matchmaker.Status{})
+	}
+}
+
+func (ws *WebSocket) startQueuedMatch(first *player.Player, second *player.Player) {
+	if !ws.inboxes.post(first.ID(), func() { ws.hostQueuedMatch(first, second) }) {
+		ws.requeue(second)
+	}
+}
+
+func (ws *WebSocket) hostQueuedMatch(first *player.Player, second *player.Player) {
+	if first.GetRoomID() != "" {
+		ws.requeue(second)
+		return
+	}
+
+	roomCode, err := ws.rm.CreateRoom(first.ID(), room.RoomSettings{
+		Record:      true,
+		MaxPlayers:  2,
+		NeedPlayers: 2,
+	})
+	if err != nil {
+		slog.Error("Matchmaker failed to create room", "err", err)
+		ws.requeue(first, second)
+		return
+	}
+
+	if err := ws.rm.JoinRoom(first, roomCode, ""); err != nil {
+		slog.Warn("Matchmaker failed to join room", "player", first.ID(), "room", roomCode, "err", err)
+		ws.rm.DeleteRoom(roomCode)
+		ws.requeue(first, second)
+		return
+	}
+
+	if !ws.inboxes.post(second.ID(), func() { ws.joinQueuedMatch(second, first, roomCode) }) {
+		ws.cancelQueuedMatch(first, roomCode)
+		return
+	}
+
+	ws.sendQueueStatus(first, matchmaker.Status{Matched: true})
+	first.Send(message.Message{
+		Type: message.JoinRoomMsg,
+		Data: nil,
+	})
+}
+
+func (ws *WebSocket) joinQueuedMatch(second *player.Player, first *player.Player, roomCode string) {
+	if err := ws.rm.JoinRoom(second, roomCode, ""); err != nil {
+		slog.Warn("Matchmaker failed to join room", "player", second.ID(), "room", roomCode, "err", err)
+		ws.requeue(second)
+		if !ws.inboxes.post(first.ID(), func() { ws.cancelQueuedMatch(first, roomCode) }) {
+			ws.rm.DeleteRoom(roomCode)
+		}
+		return
+	}
+
+	ws.sendQueueStatus(second, matchmaker.Status{Matched: true})
+	second.Send(message.Message{
+		Type: message.JoinRoomMsg,
+		Data: nil,
+	})
+
+	if _room, err := ws.rm.GetRoom(roomCode); err == nil {
+		if err := ws.startGame(_room, first.ID()); err != nil {
+			slog.Error("Matchmaker failed to start game", "room", roomCode, "err", err)
+		}
+	}
+}
+
+func (ws *WebSocket) cancelQueuedMatch(first *player.Player, roomCode string) {
+	if first.GetRoomID() != roomCode {
+		return
+	}
+
+	ws.rm.DeleteRoom(roomCode)
+	first.Send(message.Message{
+		Type: message.LeaveRoomMsg,
+		Data: nil,
+	})
+	ws.requeue(first)
+}
+
+func (ws *WebSocket) requeue(players ...*player.Player) {
+	for _, _player := range players {
+		if _player.IsConnected() && _player.GetRoomID() == "" {
+			ws.mm.Enqueue(_player, _player.GetRating().Rating)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package wshandler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/storage"
+)
+
+func newQueueTest(t *testing.T) (*WebSocket, []*player.Player, []chan inboxItem) {
+	t.Helper()
+
+	chars, err := character.LoadCharacters(filepath.Join("..", "..", "..", "assets", "meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := DefaultSettings()
+	settings.StartLeadTime = time.Hour
+	ws := NewWebSocket(chars, storage.NewMemoryStorage(), nil, settings)
+	ws.mm.Stop()
+
+	players := []*player.Player{
+		player.NewPlayer("first", settings.Player),
+		player.NewPlayer("second", settings.Player),
+	}
+	inboxes := make([]chan inboxItem, len(players))
+	for i, p := range players {
+		inboxes[i] = ws.inboxes.open(p.ID())
+	}
+
+	return ws, players, inboxes
+}
+
+func runNext(t *testing.T, inbox chan inboxItem) {
+	t.Helper()
+
+	select {
+	case item := <-inbox:
+		if item.action == nil {
+			t.Fatalf("expected an action, got %s message", item.msg.Type)
+		}
+		item.action()
+	default:
+		t.Fatal("inbox is empty")
+	}
+}
+
+func TestQueuedMatchStartsThroughInboxes(t *testing.T) {
+	ws, players, inboxes := newQueueTest(t)
+	first, second := players[0], players[1]
+
+	ws.startQueuedMatch(first, second)
+	if first.GetRoomID() != "" || second.GetRoomID() != "" {
+		t.Fatal("matchmaker changed player state outside their inboxes")
+	}
+
+	runNext(t, inboxes[0])
+	if first.GetRoomID() == "" || second.GetRoomID() != "" {
+		t.Fatal("host action did not only move the host")
+	}
+
+	runNext(t, inboxes[1])
+	if first.GetRoomID() != second.GetRoomID() {
+		t.Fatalf("players are in rooms %q and %q", first.GetRoomID(), second.GetRoomID())
+	}
+
+	_room, err := ws.rm.GetRoom(first.GetRoomID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _room.StopMatch() })
+	if !_room.IsFighter(first.ID()) || !_room.IsFighter(second.ID()) {
+		t.Fatal("queued players are not fighting each other")
+	}
+}
+
+func TestQueuedMatchIsCancelledWhenOpponentJoinedRoom(t *testing.T) {
+	ws, players, inboxes := newQueueTest(t)
+	first, second := players[0], players[1]
+
+	ws.startQueuedMatch(first, second)
+	runNext(t, inboxes[0])
+	hostRoom := first.GetRoomID()
+
+	roomCode, err := ws.rm.CreateRoom("someone", room.RoomSettings{MaxPlayers: 2, NeedPlayers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.rm.JoinRoom(second, roomCode, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	runNext(t, inboxes[1])
+	runNext(t, inboxes[0])
+
+	if first.GetRoomID() != "" {
+		t.Fatalf("host is still in room %q", first.GetRoomID())
+	}
+	if _, err := ws.rm.GetRoom(hostRoom); err == nil {
+		t.Fatal("cancelled room was not deleted")
+	}
+	if second.GetRoomID() != roomCode {
+		t.Fatalf("opponent was moved to room %q", second.GetRoomID())
+	}
+}
+
+func TestJoiningRoomLeavesQueue(t *testing.T) {
+	ws, players, _ := newQueueTest(t)
+	first, second := players[0], players[1]
+
+	ws.handleCreateRoom(first, message.Message{
+		Type: message.CreateRoomMsg,
+		Data: room.RoomSettings{MaxPlayers: 2, NeedPlayers: 2},
+	})
+	if err := ws.mm.Enqueue(second, 1500); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.handleJoinRoom(second, message.Message{
+		Type: message.JoinRoomMsg,
+		Data: first.GetRoomID(),
+	})
+	if ws.mm.Status(second.ID()).InQueue {
+		t.Fatal("player is still queued after joining a room")
+	}
+
+	other, err := ws.rm.CreateRoom(second.ID(), room.RoomSettings{MaxPlayers: 2, NeedPlayers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.rm.JoinRoom(second, other, ""); err == nil {
+		t.Fatal("player joined a second room")
+	}
+	if err := ws.rm.SpectateRoom(second, other, ""); err == nil {
+		t.Fatal("player spectated a second room")
+	}
+}
@@ -0,0 +1,270 @@
+package wshandler
+
+import (
+	"fmt"
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/utils"
+)
+
+func parseJoinRoomRequest(data interface{}) message.JoinRoomRequest {
+	if roomCode, ok := data.(string); ok {
+		return message.JoinRoomRequest{RoomID: roomCode}
+	}
+
+	var request message.JoinRoomRequest
+	utils.ParseInterfaceToJSON(data, &request)
+
+	return request
+}
+
+func (ws *WebSocket) ownedRoom(_player *player.Player) (*room.Room, error) {
+	roomCode := _player.GetRoomID()
+	if roomCode == "" {
+		return nil, fmt.Errorf("player is not in any room")
+	}
+
+	_room, err := ws.rm.GetRoom(roomCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if _room.GetOwnerID() != _player.ID() {
+		return nil, fmt.Errorf("only the room owner can do this")
+	}
+
+	return _room, nil
+}
+
+func (ws *WebSocket) handleKickPlayer(_player *player.Player, msg message.Message) {
+	_room, err := ws.ownedRoom(_player)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	var request message.KickRequest
+	if err := utils.ParseInterfaceToJSON(msg.Data, &request); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	if request.PlayerID == _player.ID() {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "you cannot kick yourself",
+		})
+		return
+	}
+
+	target, err := _room.GetMember(request.PlayerID)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	if _room.IsFighter(target.ID()) {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "fighters cannot be kicked while a match is running",
+		})
+		return
+	}
+
+	kind := message.PlayerKickedEvent
+	if request.BanSeconds > 0 {
+		_room.Ban(target.ID(), time.Duration(request.BanSeconds)*time.Second)
+		kind = message.PlayerBannedEvent
+	}
+
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:       kind,
+		ActorID:    _player.ID(),
+		PlayerID:   target.ID(),
+		PlayerName: target.GetName(),
+	})
+
+	if err := ws.rm.KickFromRoom(target, _room.ID()); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+	target.Send(message.Message{
+		Type: message.LeaveRoomMsg,
+		Data: nil,
+	})
+
+	_room.Broadcast(message.Message{
+		Type: message.PlayerLeftMsg,
+		Data: target.ID(),
+	}, nil)
+}
+
+func (ws *WebSocket) handleMutePlayer(_player *player.Player, msg message.Message) {
+	_room, err := ws.ownedRoom(_player)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	var request message.MuteRequest
+	if err := utils.ParseInterfaceToJSON(msg.Data, &request); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	if request.PlayerID == _player.ID() {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "you cannot mute yourself",
+		})
+		return
+	}
+
+	target, err := _room.GetMember(request.PlayerID)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	kind := message.PlayerUnmutedEvent
+	if request.Seconds > 0 {
+		_room.Mute(target.ID(), time.Duration(request.Seconds)*time.Second)
+		kind = message.PlayerMutedEvent
+	} else {
+		_room.Unmute(target.ID())
+	}
+
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:       kind,
+		ActorID:    _player.ID(),
+		PlayerID:   target.ID(),
+		PlayerName: target.GetName(),
+	})
+}
+
+func (ws *WebSocket) handleLockRoom(_player *player.Player, msg message.Message) {
+	_room, err := ws.ownedRoom(_player)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	locked, _ := msg.Data.(bool)
+	_room.SetLocked(locked)
+	ws.rm.NotifyRoomChanged(_room.ID())
+
+	kind := message.RoomUnlockedEvent
+	if locked {
+		kind = message.RoomLockedEvent
+	}
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:    kind,
+		ActorID: _player.ID(),
+	})
+}
+
+func (ws *WebSocket) handleSetRoomPassword(_player *player.Player, msg message.Message) {
+	_room, err := ws.ownedRoom(_player)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	password, _ := msg.Data.(string)
+	if err := _room.SetPassword(password); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+	ws.rm.NotifyRoomChanged(_room.ID())
+
+	kind := message.PasswordClearedEvent
+	if password != "" {
+		kind = message.PasswordSetEvent
+	}
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:    kind,
+		ActorID: _player.ID(),
+	})
+}
+
+func (ws *WebSocket) handleTransferOwner(_player *player.Player, msg message.Message) {
+	_room, err := ws.ownedRoom(_player)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	targetID, _ := msg.Data.(string)
+	if targetID == _player.ID() {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "you already own this room",
+		})
+		return
+	}
+
+	target, err := _room.GetPlayer(targetID)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "new owner must be a player in the room",
+		})
+		return
+	}
+
+	_room.SetOwnerID(target.ID())
+	ws.rm.NotifyRoomChanged(_room.ID())
+
+	ws.broadcastRoomEvent(_room, message.RoomEvent{
+		Kind:       message.OwnerChangedEvent,
+		ActorID:    _player.ID(),
+		PlayerID:   target.ID(),
+		PlayerName: target.GetName(),
+	})
+}
+
+func (ws *WebSocket) broadcastRoomEvent(_room *room.Room, event message.RoomEvent) {
+	_room.Broadcast(message.Message{
+		Type: message.RoomEventMsg,
+		Data: event,
+	}, nil)
+	_room.Broadcast(message.Message{
+		Type: message.UpdateRoomInfoMsg,
+		Data: _room.RoomInfo(),
+	}, nil)
+}
@@ -0,0 +1,73 @@
+package wshandler
+
+import (
+	"testing"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+)
+
+func TestOwnerCannotKickFighterDuringMatch(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 0)
+	owner, opponent := members[0], members[1]
+
+	ws.handleKickPlayer(owner, message.Message{
+		Type: message.KickPlayerMsg,
+		Data: message.KickRequest{PlayerID: opponent.ID()},
+	})
+
+	if _room.GetMatch() == nil {
+		t.Fatal("kick ended the match")
+	}
+	if _, err := _room.GetPlayer(opponent.ID()); err != nil {
+		t.Fatal("fighter was kicked during the match")
+	}
+	if results, _ := ws.store.ListMatchResults(opponent.ID(), 1); len(results) != 0 {
+		t.Fatalf("kick recorded a result: %+v", results)
+	}
+}
+
+func TestOwnerCanKickSpectatorDuringMatch(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 0)
+
+	ws.handleKickPlayer(members[0], message.Message{
+		Type: message.KickPlayerMsg,
+		Data: message.KickRequest{PlayerID: members[2].ID()},
+	})
+
+	if _room.IsSpectator(members[2].ID()) {
+		t.Fatal("spectator was not kicked")
+	}
+	if _room.GetMatch() == nil {
+		t.Fatal("kicking a spectator ended the match")
+	}
+}
+
+func TestOwnerMutesAndUnmutesMember(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 0)
+	owner, watcher := members[0], members[2]
+
+	mute := func(_player *player.Player, seconds int) {
+		ws.handleMutePlayer(_player, message.Message{
+			Type: message.MutePlayerMsg,
+			Data: message.MuteRequest{PlayerID: watcher.ID(), Seconds: seconds},
+		})
+	}
+
+	mute(members[1], 600)
+	if _room.Mutes().IsMuted(watcher.ID()) {
+		t.Fatal("a non-owner muted a member")
+	}
+
+	mute(owner, 600)
+	if muted := _room.RoomInfo().MutedPlayers; len(muted) != 1 || muted[0] != watcher.ID() {
+		t.Fatalf("muted players are %v, want only %s", muted, watcher.ID())
+	}
+	if _, err := ws.chat.Moderate(watcher.ID(), "hello", _room.Mutes()); err == nil {
+		t.Fatal("muted member can still chat")
+	}
+
+	mute(owner, 0)
+	if _, err := ws.chat.Moderate(watcher.ID(), "hello", _room.Mutes()); err != nil {
+		t.Fatalf("unmuted member cannot chat: %v", err)
+	}
+}
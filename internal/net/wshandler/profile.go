@@ -0,0 +1,62 @@
+package wshandler
+
+import (
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/utils"
+)
+
+func (ws *WebSocket) handleSetProfile(_player *player.Player, msg message.Message) {
+	var profile message.Profile
+	if err := utils.ParseInterfaceToJSON(msg.Data, &profile); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	name, err := player.ValidateName(profile.Name)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	roomCode := _player.GetRoomID()
+	if roomCode == "" {
+		_player.SetName(name)
+	} else {
+		_room, err := ws.rm.GetRoom(roomCode)
+		if err != nil {
+			_player.Send(message.Message{
+				Type: message.ErrorMsg,
+				Data: err.Error(),
+			})
+			return
+		}
+
+		if err := _room.RenamePlayer(_player, name); err != nil {
+			_player.Send(message.Message{
+				Type: message.ErrorMsg,
+				Data: err.Error(),
+			})
+			return
+		}
+
+		ws.rm.NotifyRoomChanged(roomCode)
+		_room.Broadcast(message.Message{
+			Type: message.UpdateRoomInfoMsg,
+			Data: _room.RoomInfo(),
+		}, nil)
+	}
+
+	ws.saveProfile(_player)
+
+	_player.Send(message.Message{
+		Type: message.UpdatePlayerInfoMsg,
+		Data: _player.PlayerInfo(),
+	})
+}
@@ -0,0 +1,87 @@
+package wshandler
+
+import (
+	"log"
+	"log/slog"
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/rating"
+	"webgl-app/internal/storage"
+)
+
+func (ws *WebSocket) recordResult(_room *room.Room, record room.MatchRecord, forfeiterID string) {
+	m := record.Match
+	ids := m.Players()
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+		return
+	}
+
+	players := make([]*player.Player, len(ids))
+	for i, id := range ids {
+		_player, err := _room.GetPlayer(id)
+		if err != nil {
+			slog.Warn("Result not recorded, player left the room", "room", _room.ID(), "player", id)
+			return
+		}
+		players[i] = _player
+	}
+
+	var scores [2]float64
+	switch {
+	case m.IsOver():
+		winner := m.Winner()
+		for i, id := range ids {
+			switch winner {
+			case "":
+				scores[i] = rating.Draw
+			case id:
+				scores[i] = rating.Win
+			default:
+				scores[i] = rating.Loss
+			}
+		}
+	case forfeiterID != "":
+		if forfeiterID != ids[0] && forfeiterID != ids[1] {
+			slog.Warn("Result not recorded, forfeiter is not a fighter in the match", "room", _room.ID(), "player", forfeiterID)
+			return
+		}
+		for i, id := range ids {
+			if id == forfeiterID {
+				scores[i] = rating.Loss
+			} else {
+				scores[i] = rating.Win
+			}
+		}
+	default:
+		return
+	}
+
+	err := ws.store.SaveMatchResult(storage.MatchResult{
+		ID:          record.GameData.MatchID,
+		RoomID:      _room.ID(),
+		PlayerIDs:   ids,
+		Scores:      scores[:],
+		ForfeiterID: forfeiterID,
+		PlayedAt:    time.Now(),
+	})
+	if err != nil {
+		slog.Error("Failed to save match result", "room", _room.ID(), "err", err)
+	}
+
+	ratings := [2]rating.Rating{players[0].GetRating(), players[1].GetRating()}
+	for i, _player := range players {
+		opponent := ratings[1-i]
+		_player.SetRating(rating.Update(ratings[i], []rating.Result{{Opponent: opponent, Score: scores[i]}}))
+		if err := ws.store.SaveRating(_player.ID(), _player.GetRating()); err != nil {
+			slog.Error("Failed to save rating", "player", _player.ID(), "err", err)
+		}
+		_player.Send(message.Message{
+			Type: message.UpdatePlayerInfoMsg,
+			Data: _player.PlayerInfo(),
+		})
+	}
+
+	log.Printf("Room %s: recorded result %s %.1f - %.1f %s", _room.ID(), ids[0], scores[0], scores[1], ids[1])
+}
@@ -0,0 +1,37 @@
+package wshandler
+
+import "testing"
+
+func TestNonFighterForfeitIsNotRecorded(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 0)
+	spectator := members[2]
+
+	ws.handleEndGame(spectator)
+	if _room.GetMatch() == nil {
+		t.Fatal("spectator ended the match")
+	}
+
+	ws.endGame(_room, spectator.ID())
+	for _, fighter := range members[:2] {
+		if results, _ := ws.store.ListMatchResults(fighter.ID(), 1); len(results) != 0 {
+			t.Fatalf("forfeit by a non-fighter was recorded: %+v", results)
+		}
+		if info := fighter.PlayerInfo(); info.Rating != 1500 {
+			t.Errorf("rating of %s changed to %.1f", info.Name, info.Rating)
+		}
+	}
+}
+
+func TestFighterForfeitIsRecorded(t *testing.T) {
+	ws, _, members := newTestMatch(t, 0)
+
+	ws.handleEndGame(members[0])
+
+	results, err := ws.store.ListMatchResults(members[0].ID(), 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("forfeit was not recorded: %v %v", results, err)
+	}
+	if results[0].ForfeiterID != members[0].ID() {
+		t.Errorf("forfeiter is %q, want %q", results[0].ForfeiterID, members[0].ID())
+	}
+}
@@ -0,0 +1,60 @@
+package wshandler
+
+import (
+	"time"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/utils"
+)
+
+func (ws *WebSocket) handleSetReady(_player *player.Player, msg message.Message) {
+	roomCode := _player.GetRoomID()
+	if roomCode == "" {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: "player is not in any room",
+		})
+		return
+	}
+
+	_room, err := ws.rm.GetRoom(roomCode)
+	if err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	ready, _ := msg.Data.(bool)
+	if err := _room.SetReady(_player.ID(), ready); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	ws.rm.NotifyRoomChanged(roomCode)
+	_room.Broadcast(message.Message{
+		Type: message.UpdateRoomInfoMsg,
+		Data: _room.RoomInfo(),
+	}, nil)
+}
+
+func (ws *WebSocket) handleTimeSync(_player *player.Player, msg message.Message) {
+	var sync message.TimeSync
+	if err := utils.ParseInterfaceToJSON(msg.Data, &sync); err != nil {
+		_player.Send(message.Message{
+			Type: message.ErrorMsg,
+			Data: err.Error(),
+		})
+		return
+	}
+
+	sync.ServerTime = time.Now().UnixMilli()
+	_player.Send(message.Message{
+		Type: message.TimeSyncMsg,
+		Data: sync,
+	})
+}
@@ -0,0 +1,32 @@
+package wshandler
+
+import (
+	"log/slog"
+	"time"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/replay"
+)
+
+func (ws *WebSocket) saveReplay(_room *room.Room, record room.MatchRecord) {
+	if ws.replays == nil || len(record.Inputs) == 0 {
+		return
+	}
+
+	fighters := record.Match.Fighters()
+	characters := make([]string, len(fighters))
+	for i, f := range fighters {
+		characters[i] = f.Character.Name
+	}
+
+	r := replay.New(replay.Header{
+		MatchID:    record.GameData.MatchID,
+		RoomID:     _room.ID(),
+		RecordedAt: time.Now(),
+		Characters: characters,
+		StartGame:  record.GameData,
+	}, record.Inputs)
+
+	if err := ws.replays.Save(r); err != nil {
+		slog.Error("Failed to save replay", "room", _room.ID(), "match", record.GameData.MatchID, "err", err)
+	}
+}
@@ -0,0 +1,97 @@
+package wshandler
+
+import (
+	"sync"
+	"time"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/utils"
+)
+
+const sessionTokenLength = 32
+
+type session struct {
+	player *player.Player
+	expiry *time.Timer
+}
+
+type sessions struct {
+	entries map[string]*session
+	mu      sync.Mutex
+}
+
+func newSessions() *sessions {
+	return &sessions{
+		entries: make(map[string]*session),
+	}
+}
+
+func (s *sessions) create(_player *player.Player) (string, error) {
+	token, err := utils.GenerateToken(sessionTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[token] = &session{
+		player: _player,
+	}
+
+	return token, nil
+}
+
+func (s *sessions) add(token string, _player *player.Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[token] = &session{
+		player: _player,
+	}
+}
+
+func (s *sessions) resume(token string) (*player.Player, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[token]
+	if !exists {
+		return nil, false
+	}
+
+	if entry.expiry != nil {
+		entry.expiry.Stop()
+		entry.expiry = nil
+	}
+
+	return entry.player, true
+}
+
+func (s *sessions) suspend(token string, gracePeriod time.Duration, onExpire func(*player.Player)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[token]
+	if !exists {
+		return
+	}
+
+	if entry.expiry != nil {
+		entry.expiry.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(gracePeriod, func() {
+		s.mu.Lock()
+		entry, exists := s.entries[token]
+		if !exists || entry.expiry != timer {
+			s.mu.Unlock()
+			return
+		}
+		delete(s.entries, token)
+		s.mu.Unlock()
+
+		onExpire(entry.player)
+	})
+	entry.expiry = timer
+}
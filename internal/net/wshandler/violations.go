@@ -0,0 +1,23 @@
+package wshandler
+
+import (
+	"log/slog"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+)
+
+func (ws *WebSocket) reportViolation(_room *room.Room, _player *player.Player, err error) {
+	if !_room.IsFighter(_player.ID()) {
+		return
+	}
+
+	count := _room.AddViolation(_player.ID())
+	slog.Warn("Player sent invalid input", "room", _room.ID(), "player", _player.ID(), "name", _player.GetName(), "violations", count, "max", ws.settings.MaxInputViolations, "err", err)
+
+	if ws.settings.MaxInputViolations <= 0 || count != ws.settings.MaxInputViolations {
+		return
+	}
+
+	slog.Warn("Player forfeits the match after input violations", "room", _room.ID(), "player", _player.ID(), "violations", count)
+	ws.endGame(_room, _player.ID())
+}
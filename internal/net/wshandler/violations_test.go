@@ -0,0 +1,121 @@
+package wshandler
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/net/message"
+	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/room"
+	"webgl-app/internal/storage"
+)
+
+func newTestMatch(t *testing.T, maxViolations int) (*WebSocket, *room.Room, []*player.Player) {
+	t.Helper()
+
+	chars, err := character.LoadCharacters(filepath.Join("..", "..", "..", "assets", "meta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := DefaultSettings()
+	settings.MaxInputViolations = maxViolations
+	settings.StartLeadTime = time.Hour
+	ws := NewWebSocket(chars, storage.NewMemoryStorage(), nil, settings)
+
+	members := []*player.Player{
+		player.NewPlayer("first", settings.Player),
+		player.NewPlayer("second", settings.Player),
+		player.NewPlayer("watcher", settings.Player),
+	}
+	roomCode, err := ws.rm.CreateRoom(members[0].ID(), room.RoomSettings{MaxPlayers: 2, NeedPlayers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range members[:2] {
+		if err := ws.rm.JoinRoom(p, roomCode, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ws.rm.SpectateRoom(members[2], roomCode, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_room, _ := ws.rm.GetRoom(roomCode)
+	if err := ws.startGame(_room, members[0].ID()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _room.StopMatch() })
+
+	return ws, _room, members
+}
+
+func sendInput(ws *WebSocket, _player *player.Player, frame uint64) {
+	ws.handlePlayerInput(_player, message.Message{
+		Type: message.PlayerInputMsg,
+		Data: message.FrameInput{Frame: frame},
+	})
+}
+
+func TestFighterForfeitsAtViolationThreshold(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 3)
+	offender := members[1]
+
+	sendInput(ws, offender, 0)
+	sendInput(ws, offender, 0)
+	sendInput(ws, offender, 0)
+	if _room.GetMatch() == nil {
+		t.Fatal("match ended before the violation threshold")
+	}
+
+	sendInput(ws, offender, 1_000_000)
+	if _room.GetMatch() != nil {
+		t.Fatal("match is still running after the violation threshold")
+	}
+
+	results, err := ws.store.ListMatchResults(offender.ID(), 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("forfeit was not recorded: %v %v", results, err)
+	}
+	if results[0].ForfeiterID != offender.ID() {
+		t.Errorf("forfeiter is %q, want %q", results[0].ForfeiterID, offender.ID())
+	}
+}
+
+func TestSpectatorInputIsIgnored(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 3)
+	spectator := members[2]
+
+	for i := 0; i < 10; i++ {
+		sendInput(ws, spectator, 1_000_000)
+		ws.reportViolation(_room, spectator, fmt.Errorf("invalid input"))
+	}
+
+	if _room.GetMatch() == nil {
+		t.Fatal("spectator input ended the match")
+	}
+	if results, _ := ws.store.ListMatchResults(members[0].ID(), 1); len(results) != 0 {
+		t.Fatalf("spectator input recorded a result: %v", results)
+	}
+}
+
+func TestEndGameWithoutMatchIsNoop(t *testing.T) {
+	ws, _room, members := newTestMatch(t, 3)
+
+	ws.endGame(_room, members[0].ID())
+	if err := _room.SetReady(members[1].ID(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.endGame(_room, members[1].ID())
+	if ready := _room.RoomInfo().ReadyPlayers; len(ready) != 1 || ready[0] != members[1].ID() {
+		t.Fatalf("ready players are %v, want only %s", ready, members[1].ID())
+	}
+
+	results, _ := ws.store.ListMatchResults(members[1].ID(), 10)
+	if len(results) != 1 || results[0].ForfeiterID != members[0].ID() {
+		t.Fatalf("results are %+v, want only the forfeit of %s", results, members[0].ID())
+	}
+}
@@ -1,63 +1,263 @@
 package wshandler
 
 import (
-	"encoding/json"
 	"log"
+	"log/slog"
 	"net/http"
+	"time"
+	"webgl-app/internal/game/character"
+	"webgl-app/internal/net/chat"
+	"webgl-app/internal/net/matchmaker"
 	"webgl-app/internal/net/message"
 	"webgl-app/internal/net/player"
+	"webgl-app/internal/net/ratelimit"
 	"webgl-app/internal/net/roommanager"
+	"webgl-app/internal/replay"
+	"webgl-app/internal/storage"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+type Settings struct {
+	AllowedOrigins     []string
+	Player             player.Options
+	PongTimeout        time.Duration
+	IdleTimeout        time.Duration
+	SessionGracePeriod time.Duration
+	SessionLifetime    time.Duration
+	StartLeadTime      time.Duration
+	StartCountdown     time.Duration
+	MaxInputViolations int
+	Limits             ratelimit.Settings
+	Rooms              roommanager.Limits
+	Matchmaker         matchmaker.Settings
+	Chat               chat.Settings
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Player:             player.DefaultOptions(),
+		PongTimeout:        10 * time.Second,
+		IdleTimeout:        10 * time.Minute,
+		SessionGracePeriod: 30 * time.Second,
+		SessionLifetime:    30 * 24 * time.Hour,
+		StartLeadTime:      500 * time.Millisecond,
+		StartCountdown:     3 * time.Second,
+		MaxInputViolations: 20,
+		Limits:             ratelimit.DefaultSettings(),
+		Rooms:              roommanager.DefaultLimits(),
+		Matchmaker:         matchmaker.DefaultSettings(),
+		Chat:               chat.DefaultSettings(),
+	}
 }
 
 type WebSocket struct {
-	upgrader websocket.Upgrader
-	rm       roommanager.RoomManager
+	upgrader    websocket.Upgrader
+	rm          roommanager.RoomManager
+	connections *ratelimit.Connections
+	characters  map[string]*character.Character
+	sessions    *sessions
+	inboxes     *inboxes
+	store       storage.Storage
+	replays     *replay.Store
+	mm          *matchmaker.Matchmaker
+	chat        *chat.Moderator
+	settings    Settings
 }
 
-func NewWebSocket() *WebSocket {
-	return &WebSocket{
+func NewWebSocket(characters map[string]*character.Character, store storage.Storage, replays *replay.Store, settings Settings) *WebSocket {
+	ws := &WebSocket{
 		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool { return true },
+			CheckOrigin:       originChecker(settings.AllowedOrigins),
+			EnableCompression: true,
 		},
-		rm: *roommanager.NewRoomManager(),
+		rm:          *roommanager.NewRoomManager(settings.Rooms),
+		connections: ratelimit.NewConnections(settings.Limits.MaxConnectionsPerIP),
+		characters:  characters,
+		sessions:    newSessions(),
+		inboxes:     newInboxes(),
+		store:       store,
+		replays:     replays,
+		chat:        chat.NewModerator(settings.Chat),
+		settings:    settings,
 	}
+	ws.mm = matchmaker.NewMatchmaker(settings.Matchmaker, ws.startQueuedMatch, ws.sendQueueStatus)
+	ws.mm.Start()
+
+	return ws
 }
 
 func (ws *WebSocket) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	ip := remoteIP(r)
+	if !ws.connections.Acquire(ip) {
+		slog.Warn("Rejected connection: too many connections", "ip", ip)
+		http.Error(w, "too many connections", http.StatusTooManyRequests)
+		return
+	}
+	defer ws.connections.Release(ip)
+
+	conn, err := ws.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("Upgrade error:", err)
+		slog.Warn("Upgrade error", "err", err)
 		return
 	}
+	if ws.settings.Limits.MaxMessageSize > 0 {
+		conn.SetReadLimit(ws.settings.Limits.MaxMessageSize)
+	}
+
+	hs, err := ws.handshake(conn, r)
+	if err != nil {
+		slog.Warn("Handshake error", "err", err)
+		conn.Close()
+		return
+	}
+
+	player := hs.player
+	done := player.Done()
+	if hs.resumed {
+		log.Printf("Player %s resumed session", player.ID())
+		ws.restorePlayer(player)
+	} else {
+		log.Printf("Player %s connected", player.ID())
+	}
 
-	player := player.NewPlayer(conn, "Player")
-	log.Printf("Player %s connected", player.ID())
+	lastActivity := time.Now()
+	ws.extendReadDeadline(conn, lastActivity)
+	conn.SetPongHandler(func(string) error {
+		ws.extendReadDeadline(conn, lastActivity)
+		return nil
+	})
+
+	limiter := ratelimit.NewLimiter(ws.settings.Limits)
+	inbox := ws.inboxes.open(player.ID())
+	processed := make(chan struct{})
+	go ws.processMessages(player, inbox, processed)
 
 	defer func() {
-		ws.handleEndGame(player)
-		ws.handleLeaveRoom(player)
-		conn.Close()
+		ws.inboxes.close(player.ID(), inbox)
+		<-processed
+
+		if player.Detach(conn) {
+			ws.suspendPlayer(player, hs.token)
+		}
 	}()
 
 	for {
-		_, rdmsg, err := conn.ReadMessage()
+		msgType, rdmsg, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("Player %s disconnected", player.ID())
+			log.Printf("Player %s disconnected: %v", player.ID(), err)
 			break
 		}
 
-		var msg message.Message
-		if err := json.Unmarshal(rdmsg, &msg); err != nil {
-			log.Println("Unmarshal error:", err)
+		lastActivity = time.Now()
+		ws.extendReadDeadline(conn, lastActivity)
+
+		msg, err := decoderFor(msgType).Decode(rdmsg)
+		if err != nil {
+			slog.Warn("Decode error", "player", player.ID(), "err", err)
 			continue
 		}
 
-		go ws.handleMessage(player, msg)
+		if retryAfter, ok := limiter.Allow(msg.Type); !ok {
+			if !ws.rateLimited(conn, player, limiter, msg.Type, retryAfter) {
+				return
+			}
+			continue
+		}
+
+		select {
+		case inbox <- inboxItem{msg: msg}:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (ws *WebSocket) extendReadDeadline(conn *websocket.Conn, lastActivity time.Time) {
+	var deadline time.Time
+
+	if ws.settings.Player.PingInterval > 0 {
+		deadline = time.Now().Add(ws.settings.Player.PingInterval + ws.settings.PongTimeout)
+	}
+	if ws.settings.IdleTimeout > 0 {
+		idleDeadline := lastActivity.Add(ws.settings.IdleTimeout)
+		if deadline.IsZero() || idleDeadline.Before(deadline) {
+			deadline = idleDeadline
+		}
+	}
+
+	conn.SetReadDeadline(deadline)
+}
+
+func (ws *WebSocket) suspendPlayer(_player *player.Player, token string) {
+	ws.rm.Unsubscribe(_player.ID())
+	ws.mm.Dequeue(_player.ID())
+
+	if roomCode := _player.GetRoomID(); roomCode != "" {
+		if _room, err := ws.rm.GetRoom(roomCode); err == nil {
+			_room.SetPlayerConnected(_player.ID(), false)
+		}
+	}
+
+	ws.sessions.suspend(token, ws.settings.SessionGracePeriod, func(_player *player.Player) {
+		log.Printf("Player %s session expired", _player.ID())
+		ws.chat.Forget(_player.ID())
+		if _player.GetRoomID() != "" {
+			ws.handleEndGame(_player)
+			ws.handleLeaveRoom(_player)
+		}
+	})
+}
+
+func (ws *WebSocket) restorePlayer(_player *player.Player) {
+	roomCode := _player.GetRoomID()
+	if roomCode == "" {
+		_player.Send(message.Message{
+			Type: message.LeaveRoomMsg,
+			Data: nil,
+		})
+		return
+	}
+
+	_room, err := ws.rm.GetRoom(roomCode)
+	if err != nil {
+		_player.SetRoomID("")
+		_player.Send(message.Message{
+			Type: message.LeaveRoomMsg,
+			Data: nil,
+		})
+		return
+	}
+
+	joinType := message.JoinRoomMsg
+	if _room.IsSpectator(_player.ID()) {
+		joinType = message.SpectateRoomMsg
+	}
+
+	_room.SetPlayerConnected(_player.ID(), true)
+	_player.Send(message.Message{
+		Type: joinType,
+		Data: nil,
+	})
+
+	ws.sendChatHistory(_player, _room)
+
+	if resumeData, ok := _room.ResumeData(); ok {
+		_player.Send(message.Message{
+			Type: message.ResumeGameMsg,
+			Data: resumeData,
+		})
+	}
+}
+
+func (ws *WebSocket) processMessages(_player *player.Player, inbox <-chan inboxItem, processed chan<- struct{}) {
+	defer close(processed)
+
+	for item := range inbox {
+		if item.action != nil {
+			item.action()
+			continue
+		}
+		ws.handleMessage(_player, item.msg)
 	}
 }
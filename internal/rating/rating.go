@@ -0,0 +1,132 @@
+package rating
+
+import (
+	"math"
+)
+
+const (
+	DefaultRating     = 1500
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+	MinDeviation      = 30
+
+	Tau       = 0.5
+	scale     = 173.7178
+	tolerance = 0.000001
+)
+
+const (
+	Loss = 0
+	Draw = 0.5
+	Win  = 1
+)
+
+type Rating struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+func NewRating() Rating {
+	return Rating{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+func Update(player Rating, results []Result) Rating {
+	mu, phi := toGlicko2(player)
+
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + player.Volatility*player.Volatility)
+		return fromGlicko2(mu, phi, player.Volatility)
+	}
+
+	var variance, improvement float64
+	for _, result := range results {
+		opponentMu, opponentPhi := toGlicko2(result.Opponent)
+		g := g(opponentPhi)
+		e := expectedScore(mu, opponentMu, g)
+
+		variance += g * g * e * (1 - e)
+		improvement += g * (result.Score - e)
+	}
+	variance = 1 / variance
+	delta := variance * improvement
+
+	volatility := newVolatility(phi, player.Volatility, variance, delta)
+
+	phiStar := math.Sqrt(phi*phi + volatility*volatility)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	mu += phi * phi * improvement
+
+	return fromGlicko2(mu, phi, volatility)
+}
+
+func Expected(player Rating, opponent Rating) float64 {
+	mu, _ := toGlicko2(player)
+	opponentMu, opponentPhi := toGlicko2(opponent)
+
+	return expectedScore(mu, opponentMu, g(opponentPhi))
+}
+
+func newVolatility(phi float64, sigma float64, variance float64, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(Tau*Tau)
+	}
+
+	lower := a
+	var upper float64
+	if delta*delta > phi*phi+variance {
+		upper = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*Tau) < 0 {
+			k++
+		}
+		upper = a - k*Tau
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+	for math.Abs(upper-lower) > tolerance {
+		c := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fc := f(c)
+		if fc*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+		upper, fUpper = c, fc
+	}
+
+	return math.Exp(lower / 2)
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expectedScore(mu float64, opponentMu float64, g float64) float64 {
+	return 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+}
+
+func toGlicko2(r Rating) (float64, float64) {
+	return (r.Rating - DefaultRating) / scale, r.Deviation / scale
+}
+
+func fromGlicko2(mu float64, phi float64, volatility float64) Rating {
+	return Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  math.Max(phi*scale, MinDeviation),
+		Volatility: volatility,
+	}
+}
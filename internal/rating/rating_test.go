@@ -0,0 +1,45 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func closeTo(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestUpdateMatchesGlickmanExample(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: DefaultVolatility}, Score: Win},
+		{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: DefaultVolatility}, Score: Loss},
+		{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: DefaultVolatility}, Score: Loss},
+	}
+
+	got := Update(player, results)
+	if !closeTo(got.Rating, 1464.06, 0.01) {
+		t.Errorf("rating = %.4f, want 1464.06", got.Rating)
+	}
+	if !closeTo(got.Deviation, 151.52, 0.01) {
+		t.Errorf("deviation = %.4f, want 151.52", got.Deviation)
+	}
+	if !closeTo(got.Volatility, 0.05999, 0.00001) {
+		t.Errorf("volatility = %.6f, want 0.05999", got.Volatility)
+	}
+}
+
+func TestUpdateWithoutGamesInflatesDeviation(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	got := Update(player, nil)
+	phi := 200 / scale
+	want := math.Sqrt(phi*phi+0.06*0.06) * scale
+
+	if got.Rating != player.Rating || got.Volatility != player.Volatility {
+		t.Errorf("rating or volatility changed without games: %+v", got)
+	}
+	if !closeTo(got.Deviation, want, 0.0001) || got.Deviation <= player.Deviation {
+		t.Errorf("deviation = %.4f, want %.4f", got.Deviation, want)
+	}
+}
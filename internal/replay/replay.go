@@ -0,0 +1,200 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"webgl-app/internal/net/codec"
+	"webgl-app/internal/net/message"
+)
+
+const (
+	Version       = 1
+	FileExtension = ".replay"
+	magic         = "WGRP"
+	maxHeaderSize = 64 * 1024
+	maxFighters   = 16
+	maxFrames     = 60 * 60 * 60
+)
+
+type Header struct {
+	MatchID    string
+	RoomID     string
+	RecordedAt time.Time
+	Characters []string
+	StartGame  message.StartGameData
+}
+
+type Replay struct {
+	Header Header
+	Inputs [][]message.FighterControl
+}
+
+func New(header Header, confirmed []message.ConfirmedInputs) Replay {
+	inputs := make([][]message.FighterControl, 0, len(confirmed))
+	for _, frameInputs := range confirmed {
+		if frameInputs.Frame != uint64(len(inputs)) {
+			break
+		}
+		inputs = append(inputs, frameInputs.Controls)
+	}
+
+	return Replay{
+		Header: header,
+		Inputs: inputs,
+	}
+}
+
+func (r Replay) FrameCount() int {
+	return len(r.Inputs)
+}
+
+func (r Replay) Fighters() int {
+	fighters := len(r.Header.Characters)
+	for _, controls := range r.Inputs {
+		if len(controls) > fighters {
+			fighters = len(controls)
+		}
+	}
+	return fighters
+}
+
+func Encode(w io.Writer, r Replay) error {
+	header, err := json.Marshal(r.Header)
+	if err != nil {
+		return err
+	}
+
+	if len(r.Inputs) > maxFrames {
+		return fmt.Errorf("replay is too long")
+	}
+
+	fighters := r.Fighters()
+
+	buf := []byte(magic)
+	buf = binary.AppendUvarint(buf, Version)
+	buf = binary.AppendUvarint(buf, uint64(len(header)))
+	buf = append(buf, header...)
+	buf = binary.AppendUvarint(buf, uint64(fighters))
+	buf = binary.AppendUvarint(buf, uint64(len(r.Inputs)))
+
+	for start := 0; start < len(r.Inputs); {
+		end := start + 1
+		for end < len(r.Inputs) && sameControls(r.Inputs[start], r.Inputs[end], fighters) {
+			end++
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(end-start))
+		for i := 0; i < fighters; i++ {
+			var control message.FighterControl
+			if i < len(r.Inputs[start]) {
+				control = r.Inputs[start][i]
+			}
+			buf = append(buf, codec.EncodeControl(control))
+		}
+
+		start = end
+	}
+
+	_, err = w.Write(buf)
+	return err
+}
+
+func Decode(rd io.Reader) (Replay, error) {
+	br := bufio.NewReader(rd)
+
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return Replay{}, err
+	}
+	if string(prefix) != magic {
+		return Replay{}, fmt.Errorf("not a replay file")
+	}
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return Replay{}, err
+	}
+	if version != Version {
+		return Replay{}, fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	headerSize, err := binary.ReadUvarint(br)
+	if err != nil {
+		return Replay{}, err
+	}
+	if headerSize > maxHeaderSize {
+		return Replay{}, fmt.Errorf("replay header is too large")
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return Replay{}, err
+	}
+
+	var r Replay
+	if err := json.Unmarshal(header, &r.Header); err != nil {
+		return Replay{}, err
+	}
+
+	fighters, err := binary.ReadUvarint(br)
+	if err != nil {
+		return Replay{}, err
+	}
+	if fighters > maxFighters {
+		return Replay{}, fmt.Errorf("invalid fighters count %d", fighters)
+	}
+
+	frames, err := binary.ReadUvarint(br)
+	if err != nil {
+		return Replay{}, err
+	}
+	if frames > maxFrames {
+		return Replay{}, fmt.Errorf("invalid frames count %d", frames)
+	}
+
+	r.Inputs = make([][]message.FighterControl, 0)
+	for uint64(len(r.Inputs)) < frames {
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return Replay{}, err
+		}
+		if run == 0 || run > frames-uint64(len(r.Inputs)) {
+			return Replay{}, fmt.Errorf("invalid replay frame run")
+		}
+
+		controls := make([]message.FighterControl, fighters)
+		for i := range controls {
+			b, err := br.ReadByte()
+			if err != nil {
+				return Replay{}, err
+			}
+			controls[i] = codec.DecodeControl(b)
+		}
+
+		for i := uint64(0); i < run; i++ {
+			r.Inputs = append(r.Inputs, controls)
+		}
+	}
+
+	return r, nil
+}
+
+func sameControls(a []message.FighterControl, b []message.FighterControl, fighters int) bool {
+	for i := 0; i < fighters; i++ {
+		var ca, cb message.FighterControl
+		if i < len(a) {
+			ca = a[i]
+		}
+		if i < len(b) {
+			cb = b[i]
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
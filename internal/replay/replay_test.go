@@ -0,0 +1,100 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+	"webgl-app/internal/net/message"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	idle := []message.FighterControl{{}, {}}
+	moving := []message.FighterControl{{MoveLeft: true}, {Jump: true, Attack: true}}
+
+	var confirmed []message.ConfirmedInputs
+	for i, controls := range [][]message.FighterControl{idle, idle, idle, moving, moving, idle, {{MoveRight: true}}} {
+		confirmed = append(confirmed, message.ConfirmedInputs{Frame: uint64(i), Controls: controls})
+	}
+
+	original := New(Header{
+		MatchID:    "m1",
+		RoomID:     "ABCD",
+		RecordedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Characters: []string{"knight", "knight"},
+		StartGame:  message.StartGameData{MatchID: "m1", Level: "level_1", Seed: 42, BestOf: 3},
+	}, confirmed)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, original); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Header, original.Header) {
+		t.Fatalf("header = %+v, want %+v", decoded.Header, original.Header)
+	}
+	if decoded.FrameCount() != len(confirmed) {
+		t.Fatalf("FrameCount() = %d, want %d", decoded.FrameCount(), len(confirmed))
+	}
+	for frame, controls := range decoded.Inputs {
+		want := make([]message.FighterControl, 2)
+		copy(want, confirmed[frame].Controls)
+		if !reflect.DeepEqual(controls, want) {
+			t.Errorf("frame %d = %+v, want %+v", frame, controls, want)
+		}
+	}
+}
+
+func TestDecodeRejectsMalformedInput(t *testing.T) {
+	var valid bytes.Buffer
+	if err := Encode(&valid, Replay{Inputs: [][]message.FighterControl{{{Jump: true}}, {{}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", []byte("NOPE")},
+		{"truncated", valid.Bytes()[:valid.Len()-1]},
+		{"too many fighters", encodedPrefix(maxFighters+1, 1)},
+		{"too many frames", encodedPrefix(1, maxFrames+1)},
+		{"huge frames count", encodedPrefix(1, math.MaxUint64)},
+		{"zero run", binary.AppendUvarint(encodedPrefix(1, 2), 0)},
+		{"run past frame count", binary.AppendUvarint(encodedPrefix(1, 2), 3)},
+		{"huge run", binary.AppendUvarint(encodedPrefix(1, 2), math.MaxUint64)},
+		{"missing controls", binary.AppendUvarint(encodedPrefix(2, 2), 2)},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(bytes.NewReader(tt.data)); err == nil {
+			t.Errorf("%s: Decode succeeded", tt.name)
+		}
+	}
+}
+
+func TestEncodeRejectsTooLongReplay(t *testing.T) {
+	r := Replay{Inputs: make([][]message.FighterControl, maxFrames+1)}
+	if err := Encode(&bytes.Buffer{}, r); err == nil {
+		t.Fatal("Encode succeeded")
+	}
+}
+
+func encodedPrefix(fighters uint64, frames uint64) []byte {
+	header := []byte("{}")
+
+	buf := []byte(magic)
+	buf = binary.AppendUvarint(buf, Version)
+	buf = binary.AppendUvarint(buf, uint64(len(header)))
+	buf = append(buf, header...)
+	buf = binary.AppendUvarint(buf, fighters)
+	buf = binary.AppendUvarint(buf, frames)
+	return buf
+}
@@ -0,0 +1,150 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9-]{1,64}$`)
+
+type Info struct {
+	MatchID    string
+	RecordedAt time.Time
+	Size       int64
+}
+
+type Store struct {
+	dir      string
+	maxCount int
+	maxAge   time.Duration
+	mu       sync.Mutex
+}
+
+func NewStore(dir string, maxCount int, maxAge time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:      dir,
+		maxCount: maxCount,
+		maxAge:   maxAge,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enforceRetention(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) Save(r Replay) error {
+	if !idPattern.MatchString(r.Header.MatchID) {
+		return fmt.Errorf("invalid match id %q", r.Header.MatchID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := filepath.Join(s.dir, r.Header.MatchID+FileExtension+".tmp")
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := Encode(file, r); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path(r.Header.MatchID)); err != nil {
+		return err
+	}
+
+	return s.enforceRetention()
+}
+
+func (s *Store) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list()
+}
+
+func (s *Store) Open(matchID string) (*os.File, error) {
+	if !idPattern.MatchString(matchID) {
+		return nil, os.ErrNotExist
+	}
+
+	return os.Open(s.path(matchID))
+}
+
+func (s *Store) list() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, FileExtension) {
+			continue
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, Info{
+			MatchID:    strings.TrimSuffix(name, FileExtension),
+			RecordedAt: fileInfo.ModTime(),
+			Size:       fileInfo.Size(),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].RecordedAt.After(infos[j].RecordedAt)
+	})
+
+	return infos, nil
+}
+
+func (s *Store) enforceRetention() error {
+	infos, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, info := range infos {
+		expired := s.maxAge > 0 && now.Sub(info.RecordedAt) > s.maxAge
+		overflow := s.maxCount > 0 && i >= s.maxCount
+		if expired || overflow {
+			if err := os.Remove(s.path(info.MatchID)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) path(matchID string) string {
+	return filepath.Join(s.dir, matchID+FileExtension)
+}
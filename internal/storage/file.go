@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"webgl-app/internal/rating"
+)
+
+const (
+	stateFileName   = "state.json"
+	matchesFileName = "matches.jsonl"
+	stateFlushDelay = time.Second
+)
+
+type fileState struct {
+	Players  map[string]PlayerRecord
+	Sessions map[string]SessionRecord
+	Ratings  map[string]rating.Rating
+}
+
+type FileStorage struct {
+	*MemoryStorage
+	dir      string
+	matchLog *os.File
+	flush    *time.Timer
+	dirty    bool
+	flushMu  sync.Mutex
+	writeMu  sync.Mutex
+}
+
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &FileStorage{
+		MemoryStorage: NewMemoryStorage(),
+		dir:           dir,
+	}
+
+	if err := s.loadState(); err != nil {
+		return nil, err
+	}
+	if err := s.loadMatches(); err != nil {
+		return nil, err
+	}
+
+	matches, err := os.OpenFile(filepath.Join(dir, matchesFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s.matchLog = matches
+
+	return s, nil
+}
+
+func (s *FileStorage) SavePlayer(record PlayerRecord) error {
+	s.MemoryStorage.SavePlayer(record)
+	s.scheduleFlush()
+	return nil
+}
+
+func (s *FileStorage) SaveSession(record SessionRecord) error {
+	s.MemoryStorage.SaveSession(record)
+	s.scheduleFlush()
+	return nil
+}
+
+func (s *FileStorage) DeleteSession(token string) error {
+	s.MemoryStorage.DeleteSession(token)
+	s.scheduleFlush()
+	return nil
+}
+
+func (s *FileStorage) SaveRating(playerID string, r rating.Rating) error {
+	s.MemoryStorage.SaveRating(playerID, r)
+	s.scheduleFlush()
+	return nil
+}
+
+func (s *FileStorage) SaveMatchResult(result MatchResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.matchLog.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.matches = append(s.matches, result)
+
+	return nil
+}
+
+func (s *FileStorage) Close() error {
+	s.flushMu.Lock()
+	if s.flush != nil {
+		s.flush.Stop()
+		s.flush = nil
+	}
+	s.flushMu.Unlock()
+
+	err := s.flushState()
+	if closeErr := s.matchLog.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (s *FileStorage) scheduleFlush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.dirty = true
+	if s.flush == nil {
+		s.flush = time.AfterFunc(stateFlushDelay, func() {
+			s.flushMu.Lock()
+			s.flush = nil
+			s.flushMu.Unlock()
+
+			if err := s.flushState(); err != nil {
+				slog.Error("Failed to save state", "file", stateFileName, "err", err)
+			}
+		})
+	}
+}
+
+func (s *FileStorage) flushState() error {
+	s.flushMu.Lock()
+	dirty := s.dirty
+	s.dirty = false
+	s.flushMu.Unlock()
+
+	if !dirty {
+		return nil
+	}
+
+	if err := s.saveState(); err != nil {
+		s.flushMu.Lock()
+		s.dirty = true
+		s.flushMu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStorage) loadState() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state fileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", stateFileName, err)
+	}
+
+	if state.Players != nil {
+		s.players = state.Players
+	}
+	if state.Sessions != nil {
+		s.sessions = state.Sessions
+	}
+	if state.Ratings != nil {
+		s.ratings = state.Ratings
+	}
+	s.pruneSessions(time.Now())
+
+	return nil
+}
+
+func (s *FileStorage) loadMatches() error {
+	file, err := os.Open(filepath.Join(s.dir, matchesFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var result MatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", matchesFileName, err)
+		}
+		s.matches = append(s.matches, result)
+	}
+
+	return scanner.Err()
+}
+
+func (s *FileStorage) saveState() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	s.pruneSessions(time.Now())
+	s.prunePlayers()
+	data, err := json.Marshal(fileState{
+		Players:  s.players,
+		Sessions: s.sessions,
+		Ratings:  s.ratings,
+	})
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(s.dir, stateFileName), data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"webgl-app/internal/rating"
+)
+
+func TestFileStorageFlushesOnClose(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	s.SavePlayer(PlayerRecord{ID: "p1", Name: "alice", CreatedAt: now})
+	s.SaveSession(SessionRecord{Token: "t1", PlayerID: "p1", ExpiresAt: now.Add(time.Hour)})
+	s.SaveRating("p1", rating.NewRating())
+
+	if _, err := os.Stat(filepath.Join(dir, stateFileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("state written before the flush delay: %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if record, err := reopened.GetPlayer("p1"); err != nil || record.Name != "alice" {
+		t.Fatalf("GetPlayer = %+v, %v", record, err)
+	}
+	if _, err := reopened.GetSession("t1"); err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(matches) != 0 {
+		t.Fatalf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestFileStoragePrunesOrphanedPlayers(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	s.SavePlayer(PlayerRecord{ID: "expired", CreatedAt: now})
+	s.SaveSession(SessionRecord{Token: "old", PlayerID: "expired", ExpiresAt: now.Add(-time.Minute)})
+	s.SavePlayer(PlayerRecord{ID: "rated", CreatedAt: now})
+	s.SaveRating("rated", rating.NewRating())
+	s.SavePlayer(PlayerRecord{ID: "online", CreatedAt: now})
+	s.SaveSession(SessionRecord{Token: "live", PlayerID: "online", ExpiresAt: now.Add(time.Hour)})
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if _, err := reopened.GetPlayer("expired"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expired player kept: %v", err)
+	}
+	for _, id := range []string{"rated", "online"} {
+		if _, err := reopened.GetPlayer(id); err != nil {
+			t.Fatalf("player %s pruned: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"sync"
+	"time"
+	"webgl-app/internal/rating"
+)
+
+type MemoryStorage struct {
+	players  map[string]PlayerRecord
+	sessions map[string]SessionRecord
+	ratings  map[string]rating.Rating
+	matches  []MatchResult
+	mu       sync.RWMutex
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		players:  make(map[string]PlayerRecord),
+		sessions: make(map[string]SessionRecord),
+		ratings:  make(map[string]rating.Rating),
+		matches:  make([]MatchResult, 0),
+	}
+}
+
+func (s *MemoryStorage) SavePlayer(record PlayerRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.players[record.ID] = record
+
+	return nil
+}
+
+func (s *MemoryStorage) GetPlayer(id string) (PlayerRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, exists := s.players[id]
+	if !exists {
+		return PlayerRecord{}, ErrNotFound
+	}
+
+	return record, nil
+}
+
+func (s *MemoryStorage) SaveSession(record SessionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[record.Token] = record
+
+	return nil
+}
+
+func (s *MemoryStorage) GetSession(token string) (SessionRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, exists := s.sessions[token]
+	if !exists || time.Now().After(record.ExpiresAt) {
+		return SessionRecord{}, ErrNotFound
+	}
+
+	return record, nil
+}
+
+func (s *MemoryStorage) DeleteSession(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, token)
+
+	return nil
+}
+
+func (s *MemoryStorage) SaveRating(playerID string, r rating.Rating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ratings[playerID] = r
+
+	return nil
+}
+
+func (s *MemoryStorage) GetRating(playerID string) (rating.Rating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, exists := s.ratings[playerID]
+	if !exists {
+		return rating.Rating{}, ErrNotFound
+	}
+
+	return r, nil
+}
+
+func (s *MemoryStorage) SaveMatchResult(result MatchResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.matches = append(s.matches, result)
+
+	return nil
+}
+
+func (s *MemoryStorage) ListMatchResults(playerID string, limit int) ([]MatchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]MatchResult, 0)
+	for i := len(s.matches) - 1; i >= 0; i-- {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+
+		result := s.matches[i]
+		for _, id := range result.PlayerIDs {
+			if playerID == "" || id == playerID {
+				results = append(results, result)
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) pruneSessions(now time.Time) {
+	for token, record := range s.sessions {
+		if now.After(record.ExpiresAt) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+func (s *MemoryStorage) prunePlayers() {
+	live := make(map[string]bool, len(s.sessions))
+	for _, record := range s.sessions {
+		live[record.PlayerID] = true
+	}
+
+	for id := range s.players {
+		if _, rated := s.ratings[id]; !rated && !live[id] {
+			delete(s.players, id)
+		}
+	}
+}
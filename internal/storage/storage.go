@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"webgl-app/internal/rating"
+)
+
+const (
+	MemoryDriver = "memory"
+	FileDriver   = "file"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+type PlayerRecord struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	LastSeen  time.Time
+}
+
+type SessionRecord struct {
+	Token     string
+	PlayerID  string
+	ExpiresAt time.Time
+}
+
+type MatchResult struct {
+	ID          string
+	RoomID      string
+	PlayerIDs   []string
+	Scores      []float64
+	ForfeiterID string
+	PlayedAt    time.Time
+}
+
+type Storage interface {
+	SavePlayer(record PlayerRecord) error
+	GetPlayer(id string) (PlayerRecord, error)
+
+	SaveSession(record SessionRecord) error
+	GetSession(token string) (SessionRecord, error)
+	DeleteSession(token string) error
+
+	SaveRating(playerID string, r rating.Rating) error
+	GetRating(playerID string) (rating.Rating, error)
+
+	SaveMatchResult(result MatchResult) error
+	ListMatchResults(playerID string, limit int) ([]MatchResult, error)
+
+	Close() error
+}
+
+func Open(driver string, path string) (Storage, error) {
+	switch driver {
+	case MemoryDriver:
+		return NewMemoryStorage(), nil
+	case FileDriver:
+		return NewFileStorage(path)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
@@ -2,8 +2,10 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"math/big"
+	"reflect"
 )
 
 func GenerateRandomCode(lenght int) (string, error) {
@@ -21,7 +23,22 @@ func GenerateRandomCode(lenght int) (string, error) {
 	return string(code), nil
 }
 
+func GenerateToken(lenght int) (string, error) {
+	token := make([]byte, lenght)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
 func ParseInterfaceToJSON(msgData interface{}, output interface{}) error {
+	out := reflect.ValueOf(output)
+	if msgData != nil && out.Kind() == reflect.Pointer && !out.IsNil() && reflect.TypeOf(msgData) == out.Type().Elem() {
+		out.Elem().Set(reflect.ValueOf(msgData))
+		return nil
+	}
+
 	bytes, err := json.Marshal(msgData)
 	if err != nil {
 		return err
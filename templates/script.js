@@ -29,6 +29,26 @@ function copyLobbyCode() {
 function updateOwnerControls(isOwner) {
     const startBtn = document.getElementById('start_button');
     startBtn.style.display = isOwner ? 'block' : 'none';
+    document.getElementById('owner_controls').style.display = isOwner ? 'flex' : 'none';
+}
+
+function updateReadyButton(visible, ready) {
+    const readyBtn = document.getElementById('ready_button');
+    readyBtn.style.display = visible ? 'block' : 'none';
+    readyBtn.textContent = ready ? 'Not Ready' : 'Ready';
+    readyBtn.classList.toggle('active', ready);
+}
+
+function updateCountdown(seconds) {
+    const countdown = document.getElementById('countdown');
+    countdown.textContent = seconds > 0 ? seconds : '';
+    countdown.classList.toggle('visible', seconds > 0);
+}
+
+function applyRoomPassword() {
+    const input = document.getElementById('new_room_password');
+    window.setRoomPassword(input.value);
+    input.value = '';
 }
 
 function switchStartButtonState(isEnabled) {
@@ -42,6 +62,245 @@ function switchStartButtonState(isEnabled) {
     }
 }
 
+function renderRoomList(rooms, page, pageCount) {
+    const list = document.getElementById('room_list');
+    list.replaceChildren();
+
+    if (rooms.length == 0) {
+        const empty = document.createElement('div');
+        empty.className = 'room-empty';
+        empty.textContent = 'No lobbies found';
+        list.appendChild(empty);
+    }
+
+    rooms.forEach(room => {
+        const entry = document.createElement('div');
+        entry.className = 'room-entry';
+        entry.onclick = () => {
+            if (room.password) {
+                const password = prompt('Room password');
+                if (password !== null) window.joinListedRoom(room.id, password);
+            } else {
+                window.joinListedRoom(room.id);
+            }
+        };
+
+        const code = document.createElement('span');
+        code.className = 'room-entry-code';
+        code.textContent = room.id;
+
+        const details = document.createElement('span');
+        details.className = 'room-entry-details';
+        details.textContent = `${room.status} · ${room.players}/${room.maxPlayers} · ${room.spectators} watching`;
+        if (room.bestOf > 1) details.textContent += ` · best of ${room.bestOf}`;
+        if (room.locked) details.textContent += ' · locked';
+        else if (room.password) details.textContent += ' · password';
+
+        entry.append(code, details);
+        list.appendChild(entry);
+    });
+
+    document.getElementById('room_page').textContent = `${page + 1}/${Math.max(pageCount, 1)}`;
+}
+
+function openReplayFile(input) {
+    const file = input.files[0];
+    input.value = '';
+    if (!file) return;
+
+    file.arrayBuffer().then(buffer => {
+        window.loadReplay(new Uint8Array(buffer));
+    }).catch(err => {
+        showError('Failed to read replay file');
+        console.error('Failed to read replay:', err);
+    });
+}
+
+function showReplayControls(visible) {
+    const controls = document.getElementById('replay_controls');
+    controls.classList.toggle('visible', visible);
+
+    if (visible) {
+        document.getElementById('replay_speed').value = '1';
+        document.getElementById('replay_colliders').checked = false;
+    }
+}
+
+function updateReplayControls(frame, frameCount, paused, speed) {
+    const seek = document.getElementById('replay_seek');
+    seek.max = frameCount;
+    if (document.activeElement !== seek) {
+        seek.value = frame;
+    }
+
+    document.getElementById('replay_frame').textContent = `${frame}/${frameCount}`;
+    document.getElementById('replay_pause').textContent = paused ? 'Play' : 'Pause';
+    document.getElementById('replay_speed').value = String(speed);
+}
+
+function appendChatMessage(name, text, isSystem, isOwn) {
+    document.querySelectorAll('.chat-log').forEach(log => {
+        const line = document.createElement('div');
+        line.className = 'chat-line';
+        if (isSystem) line.classList.add('system');
+        if (isOwn) line.classList.add('own');
+
+        if (!isSystem) {
+            const author = document.createElement('span');
+            author.className = 'chat-author';
+            author.textContent = name + ': ';
+            line.appendChild(author);
+        }
+        line.appendChild(document.createTextNode(text));
+
+        log.appendChild(line);
+        while (log.children.length > 50) {
+            log.removeChild(log.firstChild);
+        }
+        log.scrollTop = log.scrollHeight;
+    });
+}
+
+function clearChat() {
+    document.querySelectorAll('.chat-log').forEach(log => log.replaceChildren());
+}
+
+function chatKeyDown(event, input) {
+    event.stopPropagation();
+
+    if (event.key === 'Enter') {
+        const text = input.value.trim();
+        input.value = '';
+        if (text) window.sendChat(text);
+        if (input.closest('#game_chat')) input.blur();
+    } else if (event.key === 'Escape') {
+        input.value = '';
+        input.blur();
+    }
+}
+
+document.addEventListener('keydown', event => {
+    if (event.key !== 'Enter' || event.target.tagName === 'INPUT') return;
+    if (!document.getElementById('game_screen').classList.contains('visible')) return;
+    if (document.getElementById('replay_controls').classList.contains('visible')) return;
+
+    event.preventDefault();
+    document.querySelector('#game_chat .chat-input').focus();
+});
+
+function saveProfile() {
+    const input = document.getElementById('profile_name');
+    const name = input.value.trim();
+    if (!name) return;
+
+    window.setProfile(name);
+    input.value = '';
+}
+
+function renderRoomMembers(players, spectators, localId, ownerId) {
+    const members = document.getElementById('room_members');
+    members.replaceChildren();
+
+    const isOwner = localId === ownerId;
+    const addButton = (parent, label, onclick) => {
+        const button = document.createElement('button');
+        button.className = 'member-btn';
+        button.textContent = label;
+        button.onclick = onclick;
+        parent.appendChild(button);
+    };
+
+    const addGroup = (title, list, canOwn) => {
+        if (list.length == 0) return;
+
+        const group = document.createElement('div');
+        group.className = 'room-members-group';
+
+        const header = document.createElement('div');
+        header.textContent = title;
+        group.appendChild(header);
+
+        list.forEach(m => {
+            const member = document.createElement('div');
+            member.className = m.id === localId ? 'room-member own' : 'room-member';
+
+            const name = document.createElement('span');
+            name.textContent = m.id === ownerId ? m.name + ' (owner)' : m.name;
+            member.appendChild(name);
+
+            if (canOwn) {
+                const ready = document.createElement('span');
+                ready.className = m.ready ? 'member-ready' : 'member-ready waiting';
+                ready.textContent = m.ready ? 'ready' : 'not ready';
+                member.appendChild(ready);
+            }
+
+            if (isOwner && m.id !== localId) {
+                addButton(member, 'Kick', () => window.kickPlayer(m.id, 0));
+                addButton(member, 'Ban', () => window.kickPlayer(m.id, 600));
+                addButton(member, m.muted ? 'Unmute' : 'Mute', () => window.mutePlayer(m.id, m.muted ? 0 : 600));
+                if (canOwn) addButton(member, 'Make owner', () => window.transferOwner(m.id));
+            }
+
+            group.appendChild(member);
+        });
+
+        members.appendChild(group);
+    };
+
+    addGroup('Fighters', players, true);
+    addGroup('Watching', spectators, false);
+}
+
+function createTextImage(text) {
+    const fontSize = 24;
+    const font = `600 ${fontSize}px 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif`;
+
+    const canvas = document.createElement('canvas');
+    const ctx = canvas.getContext('2d');
+    ctx.font = font;
+    canvas.width = Math.ceil(ctx.measureText(text).width) + 8;
+    canvas.height = fontSize + 12;
+
+    ctx.font = font;
+    ctx.textAlign = 'center';
+    ctx.textBaseline = 'middle';
+    ctx.lineWidth = 4;
+    ctx.strokeStyle = '#121212';
+    ctx.fillStyle = '#f0f0f0';
+    ctx.strokeText(text, canvas.width / 2, canvas.height / 2);
+    ctx.fillText(text, canvas.width / 2, canvas.height / 2);
+
+    return canvas;
+}
+
+function createPipImage(size, filled) {
+    const canvas = document.createElement('canvas');
+    canvas.width = size;
+    canvas.height = size;
+
+    const ctx = canvas.getContext('2d');
+    ctx.beginPath();
+    ctx.arc(size / 2, size / 2, size / 2 - 3, 0, Math.PI * 2);
+    ctx.lineWidth = 3;
+    ctx.strokeStyle = '#121212';
+    ctx.fillStyle = filled ? '#f2c230' : '#4a4a4a';
+    ctx.fill();
+    ctx.stroke();
+
+    return canvas;
+}
+
+function showError(message) {
+    const banner = document.getElementById('error_banner');
+    banner.textContent = message;
+    banner.classList.add('visible');
+    clearTimeout(banner.hideTimer);
+    banner.hideTimer = setTimeout(() => {
+        banner.classList.remove('visible');
+    }, 5000);
+}
+
 function resizeCanvas() {
     const canvas = document.getElementById('game_canvas');
     if (!canvas) return;
@@ -85,6 +344,23 @@ async function loadImage(path) {
     }
 }
 
+function loadSessionToken() {
+    try {
+        return localStorage.getItem('session_token') || '';
+    } catch (e) {
+        return '';
+    }
+}
+
+function saveSessionToken(token) {
+    try {
+        if (token) localStorage.setItem('session_token', token);
+        else localStorage.removeItem('session_token');
+    } catch (e) {
+        console.warn('Session token not saved:', e);
+    }
+}
+
 function getWebSocketURL() {
     const protocol = window.location.protocol === 'https:' ? 'wss://' : 'ws://';
     return protocol + window.location.host + '/ws';